@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/internal"
+	"github.com/standoffvenus/goriffa/reader"
+)
+
+// node describes a chunk of the tree printed by dump.
+type node struct {
+	Identifier string `json:"id"`
+
+	// Offset holds the position of the chunk's header
+	// within the RIFF data.
+	Offset int64 `json:"offset"`
+	Size   int64 `json:"size"`
+
+	// Padded is set if the chunk is followed by a
+	// padding byte, i.e. its size is odd.
+	Padded bool `json:"padded"`
+
+	// ListType is only set for lists (and the RIFF data
+	// itself), which hold their sub-chunks in Chunks.
+	ListType string `json:"listType,omitempty"`
+	Chunks   []node `json:"chunks,omitempty"`
+}
+
+// tree describes the RIFF data printed by dump.
+type tree struct {
+	node
+	Warnings []warning `json:"warnings,omitempty"`
+}
+
+type warning struct {
+	Code    reader.WarningCode `json:"code"`
+	Offset  int64              `json:"offset"`
+	Message string             `json:"message"`
+}
+
+func dump(flags *flag.FlagSet, args []string, s streams) error {
+	asJSON := flags.Bool("json", false, "print the chunk tree as JSON")
+	lenient := flags.Bool("lenient", false, "read damaged RIFF data leniently, printing any warnings")
+	if err := parse(flags, args, 0, 1); err != nil {
+		return err
+	}
+
+	f, err := open(flags.Arg(0), s)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var options []reader.Option
+	if *lenient {
+		options = append(options, reader.Lenient())
+	}
+
+	// Files such as OpenDML AVI files hold several forms,
+	// each of which is printed in turn.
+	forms := reader.NewForms(f, options...)
+	for offset := int64(0); ; {
+		r, err := forms.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		t, readErr := readTree(r, offset)
+		if *asJSON {
+			enc := json.NewEncoder(s.stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(t); err != nil {
+				return err
+			}
+		} else {
+			printNode(s.stdout, t.node, 0)
+			for _, w := range t.Warnings {
+				fmt.Fprintf(s.stdout, "warning: %s at offset %d: %s\n", w.Code, w.Offset, w.Message)
+			}
+		}
+
+		// The chunks read before the error are still printed.
+		if readErr != nil {
+			return readErr
+		}
+		offset += internal.ChunkHeader{Size: t.Size}.ByteLength()
+	}
+}
+
+// readTree will read the chunk tree of the RIFF form read
+// by r, which begins at offset. If an error occurs, the
+// tree read so far is returned along with the error.
+func readTree(r *reader.Reader, offset int64) (tree, error) {
+	t := tree{node: node{
+		Identifier: r.Identifier().String(),
+		Offset:     offset,
+		Size:       int64(r.Size64()),
+		ListType:   r.FileType().String(),
+		Padded:     r.Size64()%2 == 1,
+	}}
+
+	// The "ds64" chunk of RF64 data is read by reader.New.
+	if _, ok := r.DS64(); ok {
+		ds64Offset := offset + int64(internal.LengthListHeader)
+		t.Chunks = append(t.Chunks, node{
+			Identifier: goriffa.FourCCDS64.String(),
+			Offset:     ds64Offset,
+			Size:       r.Offset() - ds64Offset - int64(internal.LengthChunkHeader),
+		})
+	}
+
+	chunks, err := readNodes(r)
+	t.Chunks = append(t.Chunks, chunks...)
+	for _, w := range r.Warnings() {
+		t.Warnings = append(t.Warnings, warning{
+			Code:    w.Code,
+			Offset:  w.Offset,
+			Message: w.Message,
+		})
+	}
+
+	return t, err
+}
+
+// readNodes will read the chunks read by r, descending
+// into lists.
+func readNodes(r *reader.Reader) ([]node, error) {
+	var nodes []node
+	for {
+		header, err := r.NextHeader()
+		if errors.Is(err, io.EOF) {
+			return nodes, nil
+		} else if err != nil {
+			return nodes, err
+		}
+
+		n := node{
+			Identifier: header.Identifier.String(),
+			Offset:     r.Offset() - int64(internal.LengthChunkHeader),
+			Size:       header.Size,
+			Padded:     header.Size%2 == 1,
+		}
+
+		if !goriffa.IsList(header.Identifier) {
+			if err := r.SkipChunk(); err != nil {
+				return append(nodes, n), err
+			}
+			nodes = append(nodes, n)
+
+			continue
+		}
+
+		l, err := r.ReadList()
+		if err != nil {
+			return append(nodes, n), err
+		}
+		n.ListType = l.FileType().String()
+		n.Chunks, err = readNodes(l)
+		nodes = append(nodes, n)
+		if err != nil {
+			return nodes, err
+		}
+	}
+}
+
+// printNode will print the provided node - indented to
+// the provided depth - followed by its sub-chunks.
+func printNode(w io.Writer, n node, depth int) {
+	var line strings.Builder
+	line.WriteString(strings.Repeat("  ", depth))
+	fmt.Fprintf(&line, "%q", n.Identifier)
+	if n.ListType != "" {
+		fmt.Fprintf(&line, " type=%q", n.ListType)
+	}
+	fmt.Fprintf(&line, " offset=%d size=%d", n.Offset, n.Size)
+	if n.Padded {
+		line.WriteString(" padded")
+	}
+	fmt.Fprintln(w, line.String())
+
+	for _, c := range n.Chunks {
+		printNode(w, c, depth+1)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/standoffvenus/goriffa/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDump(t *testing.T) {
+	wav, _ := test.WAV()
+	stdout, stderr, code := runCommand(t, wav, "dump")
+
+	assert.Equal(t, 0, code)
+	assert.Empty(t, stderr)
+	assert.Equal(t, `"RIFF" type="WAVE" offset=0 size=243800
+  "fmt " offset=12 size=16
+  "smpl" offset=36 size=60
+  "data" offset=104 size=243696
+`, stdout)
+}
+
+func TestDumpNested(t *testing.T) {
+	data := test.RIFF(
+		test.Chunk("abcd", "odd"),
+		test.List("INFO",
+			test.Chunk("INAM", "name"),
+			test.List("sub ", test.Chunk("ICMT", "x"))),
+		test.Chunk("efgh", "1234"))
+
+	stdout, _, code := runCommand(t, bytes.NewReader(data), "dump")
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, `"RIFF" type="\n\f\x0e\x10" offset=0 size=74
+  "abcd" offset=12 size=3 padded
+  "LIST" type="INFO" offset=24 size=38
+    "INAM" offset=36 size=4
+    "LIST" type="sub " offset=48 size=14
+      "ICMT" offset=60 size=1 padded
+  "efgh" offset=70 size=4
+`, stdout)
+}
+
+func TestDumpJSON(t *testing.T) {
+	data := test.RIFF(
+		test.List("INFO", test.Chunk("INAM", "odd")),
+		test.Chunk("efgh", "1234"))
+
+	stdout, _, code := runCommand(t, bytes.NewReader(data), "dump", "-json")
+	assert.Equal(t, 0, code)
+
+	var dumped tree
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &dumped))
+	assert.Equal(t, tree{node: node{
+		Identifier: "RIFF",
+		Size:       40,
+		ListType:   "\n\f\x0e\x10",
+		Chunks: []node{
+			{
+				Identifier: "LIST",
+				Offset:     12,
+				Size:       16,
+				ListType:   "INFO",
+				Chunks: []node{
+					{Identifier: "INAM", Offset: 24, Size: 3, Padded: true},
+				},
+			},
+			{Identifier: "efgh", Offset: 36, Size: 4},
+		},
+	}}, dumped)
+}
+
+func TestDumpForms(t *testing.T) {
+	data := append(test.RIFF(test.Chunk("abcd", "odd")), test.RIFF(test.Chunk("efgh", "1234"))...)
+
+	stdout, _, code := runCommand(t, bytes.NewReader(data), "dump")
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, `"RIFF" type="\n\f\x0e\x10" offset=0 size=16
+  "abcd" offset=12 size=3 padded
+"RIFF" type="\n\f\x0e\x10" offset=24 size=16
+  "efgh" offset=36 size=4
+`, stdout)
+}
+
+func TestDumpFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "riff")
+	assert.NoError(t, os.WriteFile(name, test.RIFF(test.Chunk("abcd", "1234")), 0o600))
+
+	stdout, _, code := runCommand(t, nil, "dump", name)
+
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, `"abcd" offset=12 size=4`)
+}
+
+func TestDumpCorrupted(t *testing.T) {
+	data := test.RIFF(
+		test.Chunk("abcd", "1234"),
+		test.Header("efgh", 16))
+
+	stdout, stderr, code := runCommand(t, bytes.NewReader(data), "dump")
+
+	// The chunks read before the error are printed.
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stdout, `"abcd" offset=12 size=4`)
+	assert.Contains(t, stderr, "goriffa dump:")
+}
+
+func TestDumpLenient(t *testing.T) {
+	data := test.RIFF(
+		test.Chunk("abcd", "1234"),
+		test.Header("efgh", 16), []byte("12"))
+
+	stdout, _, code := runCommand(t, bytes.NewReader(data), "dump", "-lenient")
+
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, `"abcd" offset=12 size=4`)
+	assert.Contains(t, stdout, "warning: truncated at offset 24")
+}
+
+func TestDumpNotRIFF(t *testing.T) {
+	_, stderr, code := runCommand(t, bytes.NewReader([]byte("not RIFF data")), "dump")
+
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "goriffa dump:")
+}
@@ -0,0 +1,516 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/internal"
+	"github.com/standoffvenus/goriffa/reader"
+	"github.com/standoffvenus/goriffa/writer"
+)
+
+// tempFileThreshold is the amount of data held in memory
+// when a RIFF file is written to the standard output,
+// see writer.TempFileThreshold.
+const tempFileThreshold = 32 * 1024 * 1024
+
+var (
+	errNotFound    error = errors.New("no such chunk")
+	errInvalidPath error = errors.New("invalid chunk path")
+	errSameFile    error = errors.New("refusing to overwrite the input file")
+)
+
+// selector selects a chunk by a path of FOURCCs separated
+// by slashes, e.g. "LIST/INAM" or "INFO/INAM": every
+// element matches a chunk's identifier or, for lists,
+// their list type. Elements shorter than four characters
+// are padded with spaces, so "fmt" matches "fmt ". Of the
+// chunks matching the path, the one at position index
+// (counting from 0) is selected.
+//
+// Paths holding brackets or "#" are chunk paths instead
+// (see reader.ParsePath), e.g. "LIST[INFO]/INAM#1"; index
+// then selects among the chunks the chunk path selects.
+//
+// The path "/" selects the RIFF data itself.
+type selector struct {
+	path      []internal.FourCC
+	chunkPath *reader.Path
+	index     int
+}
+
+func parseSelector(path string, index int) (selector, error) {
+	s := selector{index: index}
+	if index < 0 {
+		return s, fmt.Errorf("%w: negative index (%d)", errInvalidPath, index)
+	}
+
+	if strings.ContainsAny(path, "[]#") {
+		p, err := reader.ParsePath(path)
+		if err != nil {
+			return s, err
+		}
+		s.chunkPath = &p
+
+		return s, nil
+	}
+
+	trimmed := strings.Trim(path, "/")
+	if trimmed == "" {
+		if path == "" {
+			return s, fmt.Errorf("%w: empty path", errInvalidPath)
+		}
+
+		return s, nil
+	}
+
+	for _, element := range strings.Split(trimmed, "/") {
+		id, err := internal.ParseFourCC(element)
+		if err != nil {
+			return s, fmt.Errorf("%w %q: %s", errInvalidPath, path, err)
+		}
+		s.path = append(s.path, id)
+	}
+
+	return s, nil
+}
+
+// find returns the position of the selected chunk within
+// the index's entries, or -1 if the RIFF data itself is
+// selected.
+func (s selector) find(idx *reader.Index) (int, error) {
+	entries := idx.Entries()
+	if s.chunkPath != nil {
+		if matches := idx.FindAll(*s.chunkPath); s.index < len(matches) {
+			for i, e := range entries {
+				if e.Offset == matches[s.index].Offset {
+					return i, nil
+				}
+			}
+		}
+
+		return 0, fmt.Errorf("%w: %s", errNotFound, s)
+	}
+	if len(s.path) == 0 {
+		return -1, nil
+	}
+
+	seen := 0
+	for i := range entries {
+		if !s.matches(entries, i) {
+			continue
+		}
+		if seen == s.index {
+			return i, nil
+		}
+		seen++
+	}
+
+	return 0, fmt.Errorf("%w: %s", errNotFound, s)
+}
+
+// matches reports whether the entry at position i - and
+// its enclosing lists - match the path.
+func (s selector) matches(entries []reader.Entry, i int) bool {
+	for element := len(s.path) - 1; element >= 0; element-- {
+		if i == -1 {
+			return false
+		}
+
+		e := entries[i]
+		if e.Identifier != s.path[element] &&
+			!(goriffa.IsList(e.Identifier) && internal.FourCC(e.ListType) == s.path[element]) {
+			return false
+		}
+		i = e.Parent
+	}
+
+	// The outermost element must match a top-level chunk.
+	return i == -1
+}
+
+func (s selector) String() string {
+	if s.chunkPath != nil {
+		return fmt.Sprintf("%q (index %d)", s.chunkPath.String(), s.index)
+	}
+
+	elements := make([]string, len(s.path))
+	for i, id := range s.path {
+		elements[i] = id.String()
+	}
+
+	return fmt.Sprintf("%q (index %d)", strings.Join(elements, "/"), s.index)
+}
+
+// edit describes a change made by rewrite.
+type edit struct {
+	// target holds the position of the edited chunk
+	// within the index's entries; for insertions, it's
+	// the list inserted into, or -1 for the RIFF data
+	// itself.
+	target int
+
+	// insert is set if a chunk is inserted at position at
+	// (counting from 0, or -1 to append) within target.
+	insert bool
+	at     int
+
+	// chunk holds the identifier of the inserted (or
+	// replacing) chunk, whose data is read from data.
+	// If data is nil, target is deleted.
+	chunk internal.FourCC
+	data  io.Reader
+}
+
+// rewriter writes a copy of indexed RIFF data with a
+// single edit applied. Lists enclosing the edited chunk
+// are rewritten chunk by chunk; all other chunks are
+// copied verbatim.
+type rewriter struct {
+	idx     *reader.Index
+	entries []reader.Entry
+	edit    edit
+
+	// enclosing holds the positions of the lists that
+	// hold the edit.
+	enclosing map[int]bool
+}
+
+func newRewriter(idx *reader.Index, e edit) *rewriter {
+	rw := &rewriter{
+		idx:       idx,
+		entries:   idx.Entries(),
+		edit:      e,
+		enclosing: make(map[int]bool),
+	}
+
+	first := e.target
+	if !e.insert && e.target != -1 {
+		first = rw.entries[e.target].Parent
+	}
+	for i := first; i != -1; i = rw.entries[i].Parent {
+		rw.enclosing[i] = true
+	}
+
+	return rw
+}
+
+// writeList will write the chunks of the list at position
+// parent - or of the RIFF data itself if parent is -1.
+func (rw *rewriter) writeList(w *writer.Writer, parent int) error {
+	position := 0
+	for i, e := range rw.entries {
+		if e.Parent != parent {
+			continue
+		}
+
+		if rw.edit.insert && rw.edit.target == parent && rw.edit.at == position {
+			if err := rw.writeChunk(w, rw.edit.chunk, rw.edit.data); err != nil {
+				return err
+			}
+		}
+		position++
+
+		if err := rw.write(w, i); err != nil {
+			return err
+		}
+	}
+
+	if rw.edit.insert && rw.edit.target == parent && (rw.edit.at == -1 || rw.edit.at >= position) {
+		return rw.writeChunk(w, rw.edit.chunk, rw.edit.data)
+	}
+
+	return nil
+}
+
+// write will write the entry at position i, applying the
+// edit if it's the target.
+func (rw *rewriter) write(w *writer.Writer, i int) error {
+	e := rw.entries[i]
+	switch {
+	case !rw.edit.insert && rw.edit.target == i && rw.edit.data == nil:
+		return nil
+	case !rw.edit.insert && rw.edit.target == i:
+		return rw.writeChunk(w, e.Identifier, rw.edit.data)
+	case rw.enclosing[i]:
+		lw, err := w.CreateList(e.ListType)
+		if err != nil {
+			return err
+		}
+		if err := rw.writeList(lw, i); err != nil {
+			return err
+		}
+
+		return lw.Close()
+	}
+
+	return rw.writeChunk(w, e.Identifier, rw.idx.Open(e))
+}
+
+func (rw *rewriter) writeChunk(w *writer.Writer, identifier internal.FourCC, data io.Reader) error {
+	cw, err := w.BeginChunk(identifier)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(cw, data); err != nil {
+		return err
+	}
+
+	return cw.Close()
+}
+
+func extract(flags *flag.FlagSet, args []string, s streams) error {
+	index := flags.Int("index", 0, "select the `n`th chunk matching the path, counting from 0")
+	output := flags.String("o", "", "write the chunk's data to `file` instead of the standard output")
+	if err := parse(flags, args, 1, 2); err != nil {
+		return err
+	}
+
+	sel, err := parseSelector(flags.Arg(0), *index)
+	if err != nil {
+		return err
+	}
+
+	idx, closer, err := openIndex(flags.Arg(1), s)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	i, err := sel.find(idx)
+	if err != nil {
+		return err
+	} else if i == -1 {
+		return fmt.Errorf("%w: cannot extract the RIFF data itself", errInvalidPath)
+	}
+
+	out, err := create(*output, flags.Arg(1), s)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, idx.Open(idx.Entries()[i])); err != nil {
+		abort(out)
+
+		return err
+	}
+
+	return out.Close()
+}
+
+func remove(flags *flag.FlagSet, args []string, s streams) error {
+	index := flags.Int("index", 0, "select the `n`th chunk matching the path, counting from 0")
+	output := flags.String("o", "", "write the new RIFF file to `file` instead of the standard output")
+	if err := parse(flags, args, 1, 2); err != nil {
+		return err
+	}
+
+	return rewrite(flags.Arg(0), *index, flags.Arg(1), *output, s, func(target int) (edit, error) {
+		if target == -1 {
+			return edit{}, fmt.Errorf("%w: cannot delete the RIFF data itself", errInvalidPath)
+		}
+
+		return edit{target: target}, nil
+	})
+}
+
+func replace(flags *flag.FlagSet, args []string, s streams) error {
+	index := flags.Int("index", 0, "select the `n`th chunk matching the path, counting from 0")
+	output := flags.String("o", "", "write the new RIFF file to `file` instead of the standard output")
+	dataFile := flags.String("data", "", "read the chunk's new data from `file` instead of the standard input")
+	if err := parse(flags, args, 1, 2); err != nil {
+		return err
+	}
+
+	data, err := openData(*dataFile, flags.Arg(1), s)
+	if err != nil {
+		return err
+	}
+	defer data.Close()
+
+	return rewrite(flags.Arg(0), *index, flags.Arg(1), *output, s, func(target int) (edit, error) {
+		if target == -1 {
+			return edit{}, fmt.Errorf("%w: cannot replace the RIFF data itself", errInvalidPath)
+		}
+
+		return edit{target: target, data: data}, nil
+	})
+}
+
+func insert(flags *flag.FlagSet, args []string, s streams) error {
+	index := flags.Int("index", 0, "select the `n`th list matching the path, counting from 0")
+	output := flags.String("o", "", "write the new RIFF file to `file` instead of the standard output")
+	dataFile := flags.String("data", "", "read the chunk's data from `file` instead of the standard input")
+	identifier := flags.String("id", "", "the `FOURCC` identifying the inserted chunk (required)")
+	at := flags.Int("at", -1, "insert the chunk at `position` within the list, counting from 0; -1 appends it")
+	if err := parse(flags, args, 1, 2); err != nil {
+		return err
+	}
+
+	id, err := internal.ParseFourCC(*identifier)
+	if err != nil {
+		return fmt.Errorf("-id: %w", err)
+	}
+	if *at < -1 {
+		return fmt.Errorf("-at: invalid position (%d)", *at)
+	}
+
+	data, err := openData(*dataFile, flags.Arg(1), s)
+	if err != nil {
+		return err
+	}
+	defer data.Close()
+
+	return rewrite(flags.Arg(0), *index, flags.Arg(1), *output, s, func(target int) (edit, error) {
+		return edit{target: target, insert: true, at: *at, chunk: id, data: data}, nil
+	})
+}
+
+// rewrite will write a copy of the RIFF file named input
+// to the file named output, with the edit returned by
+// fn for the chunk selected by path and index applied.
+func rewrite(path string, index int, input, output string, s streams, fn func(target int) (edit, error)) error {
+	sel, err := parseSelector(path, index)
+	if err != nil {
+		return err
+	}
+
+	idx, closer, err := openIndex(input, s)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	target, err := sel.find(idx)
+	if err != nil {
+		return err
+	}
+	e, err := fn(target)
+	if err != nil {
+		return err
+	}
+	if e.insert && e.target != -1 && !goriffa.IsList(idx.Entries()[e.target].Identifier) {
+		return fmt.Errorf("%w: %s is not a list", errInvalidPath, sel)
+	}
+
+	out, err := create(output, input, s)
+	if err != nil {
+		return err
+	}
+
+	// The "ds64" chunk (or the "JUNK" chunk reserved in
+	// its place) is written by the writer itself.
+	options := []writer.Option{writer.ByteOrder(idx.ByteOrder())}
+	if id := idx.Identifier(); id == goriffa.FourCCRF64 || id == goriffa.FourCCBW64 {
+		options = append(options, writer.PromoteToRF64())
+	}
+
+	var w *writer.Writer
+	if at, ok := out.(writer.WriterWithWriterAt); ok {
+		w, err = writer.New(at, idx.FileType(), options...)
+	} else {
+		options = append(options, writer.TempFileThreshold(tempFileThreshold))
+		w, err = writer.NewBuffered(out, idx.FileType(), options...)
+	}
+	if err != nil {
+		abort(out)
+
+		return err
+	}
+
+	if err := newRewriter(idx, e).writeList(w, -1); err != nil {
+		_ = w.Close()
+		abort(out)
+
+		return err
+	}
+	if err := w.Close(); err != nil {
+		abort(out)
+
+		return err
+	}
+
+	return out.Close()
+}
+
+// openIndex will index the RIFF file with the provided
+// name, or the standard input if name is empty or "-".
+// As indexing requires random access, the standard input
+// is read into memory.
+func openIndex(name string, s streams) (*reader.Index, io.Closer, error) {
+	f, err := open(name, s)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ra, ok := f.(io.ReaderAt)
+	if !ok {
+		b, err := io.ReadAll(f)
+		if err != nil {
+			_ = f.Close()
+
+			return nil, nil, err
+		}
+		ra = bytes.NewReader(b)
+	}
+
+	idx, err := reader.NewIndex(ra)
+	if err != nil {
+		_ = f.Close()
+
+		return nil, nil, err
+	}
+
+	return idx, f, nil
+}
+
+// openData will open the file with the provided name -
+// or the standard input if name is empty or "-" - for
+// reading a chunk's data, unless the standard input
+// holds the RIFF file itself.
+func openData(name, input string, s streams) (io.ReadCloser, error) {
+	if isStdio(name) && isStdio(input) {
+		return nil, errors.New("-data is required when the RIFF file is read from the standard input")
+	}
+
+	return open(name, s)
+}
+
+// create will create the file with the provided name,
+// or return the standard output if name is empty or
+// "-". The file may not be the input file.
+func create(name, input string, s streams) (io.WriteCloser, error) {
+	if isStdio(name) {
+		return nopWriteCloser{s.stdout}, nil
+	}
+
+	if !isStdio(input) {
+		in, inErr := os.Stat(input)
+		out, outErr := os.Stat(name)
+		if inErr == nil && outErr == nil && os.SameFile(in, out) {
+			return nil, fmt.Errorf("%w %q", errSameFile, name)
+		}
+	}
+
+	return os.Create(name)
+}
+
+// abort will close the provided output, removing it if
+// it's a file, as its contents are incomplete.
+func abort(out io.WriteCloser) {
+	_ = out.Close()
+	if f, ok := out.(*os.File); ok {
+		_ = os.Remove(f.Name())
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/standoffvenus/goriffa/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectorPath(t *testing.T) {
+	data := test.RIFF(
+		test.Chunk("INAM", "top"),
+		test.List("INFO",
+			test.Chunk("INAM", "first")),
+		test.List("INFO",
+			test.Chunk("INAM", "second")))
+
+	for path, expected := range map[string]string{
+		"INAM":      "top",
+		"/INAM/":    "top",
+		"INFO/INAM": "first",
+		"LIST/INAM": "first",
+	} {
+		stdout, stderr, code := runCommand(t, bytes.NewReader(data), "extract", path)
+		assert.Equal(t, 0, code, stderr)
+		assert.Equal(t, expected, stdout, path)
+	}
+
+	stdout, _, code := runCommand(t, bytes.NewReader(data), "extract", "-index", "1", "INFO/INAM")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "second", stdout)
+}
+
+func TestSelectorChunkPath(t *testing.T) {
+	data := test.RIFF(
+		test.Chunk("INAM", "top"),
+		test.List("INFO",
+			test.Chunk("INAM", "first")),
+		test.List("INFO",
+			test.Chunk("INAM", "second"),
+			test.Chunk("INAM", "third")))
+
+	for path, expected := range map[string]string{
+		"LIST[INFO]/INAM":     "first",
+		"LIST[INFO]#1/INAM":   "second",
+		"LIST#1/INAM#1":       "third",
+		"LIST[INFO]/INAM#1":   "third",
+		"LIST[INFO]#0/INAM#0": "first",
+	} {
+		stdout, stderr, code := runCommand(t, bytes.NewReader(data), "extract", path)
+		assert.Equal(t, 0, code, stderr)
+		assert.Equal(t, expected, stdout, path)
+	}
+
+	// The index selects among every chunk matching the path.
+	stdout, _, code := runCommand(t, bytes.NewReader(data), "extract", "-index", "2", "LIST[INFO]/INAM")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "third", stdout)
+
+	_, stderr, code := runCommand(t, bytes.NewReader(data), "extract", "-index", "3", "LIST[INFO]/INAM")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "no such chunk")
+}
+
+func TestSelectorInvalid(t *testing.T) {
+	data := test.RIFF(test.Chunk("abcd", "1234"))
+
+	for _, args := range [][]string{
+		{"extract", "abcde"},
+		{"extract", "abcd//efgh"},
+		{"extract", "-index", "-1", "abcd"},
+		{"extract", "/"},
+		{"extract", "abcd#-1"},
+		{"extract", "LIST[INFO"},
+	} {
+		_, stderr, code := runCommand(t, bytes.NewReader(data), args...)
+		assert.Equal(t, 1, code, args)
+		assert.Contains(t, stderr, "invalid chunk path", args)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	wav, _ := test.WAV()
+	stdout, _, code := runCommand(t, wav, "extract", "fmt")
+
+	assert.Equal(t, 0, code)
+	assert.Len(t, stdout, 16)
+}
+
+func TestExtractList(t *testing.T) {
+	data := test.RIFF(test.List("INFO", test.Chunk("INAM", "odd")))
+
+	stdout, _, code := runCommand(t, bytes.NewReader(data), "extract", "INFO")
+
+	// A list's data begins with its list type.
+	assert.Equal(t, 0, code)
+	assert.Equal(t, string(append([]byte("INFO"), test.Chunk("INAM", "odd")...)), stdout)
+}
+
+func TestExtractToFile(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "riff")
+	output := filepath.Join(dir, "data")
+	assert.NoError(t, os.WriteFile(input, test.RIFF(test.Chunk("abcd", "1234")), 0o600))
+
+	stdout, _, code := runCommand(t, nil, "extract", "-o", output, "abcd", input)
+	assert.Equal(t, 0, code)
+	assert.Empty(t, stdout)
+
+	b, err := os.ReadFile(output)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("1234"), b)
+}
+
+func TestExtractNotFound(t *testing.T) {
+	data := test.RIFF(test.Chunk("abcd", "1234"))
+
+	_, stderr, code := runCommand(t, bytes.NewReader(data), "extract", "-index", "1", "abcd")
+
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "no such chunk")
+}
+
+func TestDelete(t *testing.T) {
+	data := test.RIFF(
+		test.Chunk("abcd", "1234"),
+		test.List("INFO",
+			test.Chunk("INAM", "name"),
+			test.Chunk("ICMT", "comment")),
+		test.Chunk("efgh", "5678"))
+
+	stdout, _, code := runCommand(t, bytes.NewReader(data), "delete", "INFO/INAM")
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, test.RIFF(
+		test.Chunk("abcd", "1234"),
+		test.List("INFO",
+			test.Chunk("ICMT", "comment")),
+		test.Chunk("efgh", "5678")), []byte(stdout))
+}
+
+func TestReplace(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "riff")
+	assert.NoError(t, os.WriteFile(input, test.RIFF(
+		test.List("INFO",
+			test.List("sub ",
+				test.Chunk("INAM", "name")),
+			test.Chunk("ICMT", "comment")),
+		test.Chunk("efgh", "5678")), 0o600))
+
+	stdout, _, code := runCommand(t, bytes.NewReader([]byte("a longer name")), "replace", "INFO/sub/INAM", input)
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, test.RIFF(
+		test.List("INFO",
+			test.List("sub ",
+				test.Chunk("INAM", "a longer name")),
+			test.Chunk("ICMT", "comment")),
+		test.Chunk("efgh", "5678")), []byte(stdout))
+}
+
+func TestReplaceToFile(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "riff")
+	data := filepath.Join(dir, "data")
+	output := filepath.Join(dir, "new")
+	assert.NoError(t, os.WriteFile(input, test.RIFF(test.Chunk("abcd", "1234"), test.Chunk("efgh", "5678")), 0o600))
+	assert.NoError(t, os.WriteFile(data, []byte("odd"), 0o600))
+
+	_, _, code := runCommand(t, nil, "replace", "-data", data, "-o", output, "efgh", input)
+	assert.Equal(t, 0, code)
+
+	b, err := os.ReadFile(output)
+	assert.NoError(t, err)
+	assert.Equal(t, test.RIFF(test.Chunk("abcd", "1234"), test.Chunk("efgh", "odd")), b)
+}
+
+func TestReplaceInputFile(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "riff")
+	data := filepath.Join(dir, "data")
+	assert.NoError(t, os.WriteFile(input, test.RIFF(test.Chunk("abcd", "1234")), 0o600))
+	assert.NoError(t, os.WriteFile(data, []byte("odd"), 0o600))
+
+	_, stderr, code := runCommand(t, nil, "replace", "-data", data, "-o", input, "abcd", input)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "refusing to overwrite the input file")
+}
+
+func TestReplaceWithoutData(t *testing.T) {
+	data := test.RIFF(test.Chunk("abcd", "1234"))
+
+	// The standard input already holds the RIFF file.
+	_, stderr, code := runCommand(t, bytes.NewReader(data), "replace", "abcd")
+
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "-data is required")
+}
+
+func TestInsert(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "riff")
+	data := test.RIFF(
+		test.Chunk("abcd", "1234"),
+		test.List("INFO",
+			test.Chunk("INAM", "name"),
+			test.Chunk("ICMT", "comment")))
+	assert.NoError(t, os.WriteFile(input, data, 0o600))
+
+	stdout, _, code := runCommand(t, bytes.NewReader([]byte("x")), "insert", "-id", "ISFT", "-at", "1", "INFO", input)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, test.RIFF(
+		test.Chunk("abcd", "1234"),
+		test.List("INFO",
+			test.Chunk("INAM", "name"),
+			test.Chunk("ISFT", "x"),
+			test.Chunk("ICMT", "comment"))), []byte(stdout))
+
+	stdout, _, code = runCommand(t, bytes.NewReader([]byte("x")), "insert", "-id", "efg", "/", input)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, test.RIFF(
+		test.Chunk("abcd", "1234"),
+		test.List("INFO",
+			test.Chunk("INAM", "name"),
+			test.Chunk("ICMT", "comment")),
+		test.Chunk("efg ", "x")), []byte(stdout))
+}
+
+func TestInsertNotAList(t *testing.T) {
+	data := test.RIFF(test.Chunk("abcd", "1234"))
+
+	_, stderr, code := runCommand(t, bytes.NewReader(data), "insert", "-id", "efgh", "-data", os.DevNull, "abcd")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "is not a list")
+
+	_, stderr, code = runCommand(t, bytes.NewReader(data), "insert", "-data", os.DevNull, "/")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "-id")
+}
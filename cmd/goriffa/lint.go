@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/standoffvenus/goriffa/validate"
+)
+
+// report holds the findings of a file checked by lint.
+type report struct {
+	File     string             `json:"file"`
+	Findings []validate.Finding `json:"findings"`
+}
+
+func lint(flags *flag.FlagSet, args []string, s streams) error {
+	asJSON := flags.Bool("json", false, "print the findings as JSON")
+	if err := parse(flags, args, 0, math.MaxInt32); err != nil {
+		return err
+	}
+
+	names := flags.Args()
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+
+	reports := make([]report, 0, len(names))
+	failed := 0
+	for _, name := range names {
+		findings, err := validateFile(name, s)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if findings == nil {
+			findings = []validate.Finding{}
+		}
+
+		reports = append(reports, report{File: name, Findings: findings})
+		if validate.HasErrors(findings) {
+			failed++
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(s.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(reports); err != nil {
+			return err
+		}
+	} else {
+		for _, r := range reports {
+			for _, f := range r.Findings {
+				fmt.Fprintf(s.stdout, "%s: %s\n", r.File, f)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files break the rules", failed, len(names))
+	}
+
+	return nil
+}
+
+// validateFile will validate the RIFF file with the
+// provided name, or the standard input if name is empty
+// or "-", which is read into memory.
+func validateFile(name string, s streams) ([]validate.Finding, error) {
+	if isStdio(name) {
+		b, err := io.ReadAll(s.stdin)
+		if err != nil {
+			return nil, err
+		}
+
+		return validate.Validate(bytes.NewReader(b), int64(len(b)))
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return validate.Validate(f, info.Size())
+}
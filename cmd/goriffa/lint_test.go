@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/standoffvenus/goriffa/internal/test"
+	"github.com/standoffvenus/goriffa/validate"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	wav, _ := test.WAV()
+	stdout, stderr, code := runCommand(t, wav, "lint")
+
+	assert.Equal(t, 0, code)
+	assert.Empty(t, stdout)
+	assert.Empty(t, stderr)
+}
+
+func TestLintErrors(t *testing.T) {
+	data := append(test.RIFFOf("abcd", test.Chunk("abcd", "1234")), "garbage"...)
+
+	stdout, stderr, code := runCommand(t, bytes.NewReader(data), "lint")
+
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "-: error at offset 24: 7 bytes of data follow the RIFF data [trailing-data]\n", stdout)
+	assert.Equal(t, "goriffa lint: 1 of 1 files break the rules\n", stderr)
+}
+
+func TestLintWarnings(t *testing.T) {
+	data := test.RIFFOf("abcd", test.Chunk("abcd", "odd"))
+	data[len(data)-1] = 0xFF
+
+	stdout, _, code := runCommand(t, bytes.NewReader(data), "lint")
+
+	// Warnings alone don't fail.
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, "[pad-byte]")
+}
+
+func TestLintFilesJSON(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good")
+	bad := filepath.Join(dir, "bad")
+	assert.NoError(t, os.WriteFile(good, test.RIFFOf("abcd", test.Chunk("abcd", "1234")), 0o600))
+	assert.NoError(t, os.WriteFile(bad, test.RIFFOf("abcd", test.Header("abcd", 100)), 0o600))
+
+	stdout, _, code := runCommand(t, nil, "lint", "-json", good, bad)
+	assert.Equal(t, 1, code)
+
+	var reports []report
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &reports))
+	assert.Equal(t, []report{
+		{File: good, Findings: []validate.Finding{}},
+		{File: bad, Findings: []validate.Finding{{
+			Severity: validate.SeverityError,
+			Code:     validate.CodeChunkSize,
+			Offset:   12,
+			Message:  `chunk "abcd" outlasts the data by 100 bytes`,
+		}}},
+	}, reports)
+}
+
+func TestLintMissingFile(t *testing.T) {
+	_, stderr, code := runCommand(t, nil, "lint", filepath.Join(t.TempDir(), "missing"))
+
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "goriffa lint:")
+}
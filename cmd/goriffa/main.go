@@ -0,0 +1,198 @@
+// Command goriffa inspects RIFF files, e.g. Wavefiles
+// (.wav), WEBP and AVI files.
+//
+// Usage:
+//  goriffa <command> [flags] [path] [file]
+//
+// The commands are:
+//
+//  dump     print the chunk tree of a RIFF file
+//  extract  write the data of a chunk to a file
+//  insert   insert a chunk into a list
+//  replace  replace the data of a chunk
+//  delete   delete a chunk
+//  lint     check RIFF files against the rules of the format
+//
+// Chunks are selected by a path of FOURCCs, such as
+// "LIST/INAM" or "INFO/INAM" (list types match as well),
+// and an index: "-index 1" selects the second match.
+// Paths holding brackets or "#" are chunk paths instead
+// (see reader.ParsePath), such as "LIST[INFO]/INAM#1".
+// Commands which modify a RIFF file write a new file to
+// the standard output (or the file passed to "-o"),
+// leaving the input untouched.
+//
+// The lint command exits with status 1 if any file breaks
+// the rules of the RIFF format (see package validate), so
+// it may gate CI pipelines.
+//
+// If no file is provided (or the file is "-"), the RIFF
+// data is read from the standard input. Run
+// "goriffa <command> -h" for the flags a command accepts.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// streams holds the standard streams a command uses.
+type streams struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// command describes a goriffa subcommand.
+type command struct {
+	// usage holds the arguments the command accepts,
+	// and description a one-line summary.
+	usage       string
+	description string
+
+	run func(flags *flag.FlagSet, args []string, s streams) error
+}
+
+var commands = map[string]command{
+	"dump": {
+		usage:       "[-json] [-lenient] [file]",
+		description: "print the chunk tree of a RIFF file",
+		run:         dump,
+	},
+	"extract": {
+		usage:       "[-index n] [-o file] path [file]",
+		description: "write the data of a chunk to a file",
+		run:         extract,
+	},
+	"insert": {
+		usage:       "-id FOURCC [-data file] [-at position] [-index n] [-o file] path [file]",
+		description: "insert a chunk into a list",
+		run:         insert,
+	},
+	"replace": {
+		usage:       "[-data file] [-index n] [-o file] path [file]",
+		description: "replace the data of a chunk",
+		run:         replace,
+	},
+	"delete": {
+		usage:       "[-index n] [-o file] path [file]",
+		description: "delete a chunk",
+		run:         remove,
+	},
+	"lint": {
+		usage:       "[-json] [file...]",
+		description: "check RIFF files against the rules of the format",
+		run:         lint,
+	},
+}
+
+// errUsage is returned by commands invoked with the wrong
+// arguments; its message has been printed already.
+var errUsage error = errors.New("usage")
+
+func main() {
+	os.Exit(run(os.Args[1:], streams{
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+	}))
+}
+
+// run will run the command named by the first argument,
+// returning the exit code: 0 on success, 1 if the command
+// failed and 2 if it was invoked incorrectly.
+func run(args []string, s streams) int {
+	if len(args) == 0 {
+		usage(s.stderr)
+
+		return 2
+	}
+
+	name := args[0]
+	if name == "help" || name == "-h" || name == "-help" || name == "--help" {
+		usage(s.stdout)
+
+		return 0
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(s.stderr, "goriffa: unknown command %q\n", name)
+		usage(s.stderr)
+
+		return 2
+	}
+
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(s.stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: goriffa %s %s\n", name, cmd.usage)
+		flags.PrintDefaults()
+	}
+
+	if err := cmd.run(flags, args[1:], s); errors.Is(err, flag.ErrHelp) {
+		return 0
+	} else if errors.Is(err, errUsage) {
+		return 2
+	} else if err != nil {
+		fmt.Fprintf(s.stderr, "goriffa %s: %s\n", name, err)
+
+		return 1
+	}
+
+	return 0
+}
+
+// parse will parse the flags of a command, which accepts
+// min to max positional arguments.
+func parse(flags *flag.FlagSet, args []string, min, max int) error {
+	if err := flags.Parse(args); errors.Is(err, flag.ErrHelp) {
+		return err
+	} else if err != nil {
+		return errUsage
+	}
+
+	if flags.NArg() < min || flags.NArg() > max {
+		flags.Usage()
+
+		return errUsage
+	}
+
+	return nil
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: goriffa <command> [flags] [path] [file]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "The commands are:")
+	fmt.Fprintln(w)
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-9s%s\n", name, commands[name].description)
+	}
+}
+
+// open will open the named file for reading, or return
+// the standard input if name is empty or "-".
+func open(name string, s streams) (io.ReadCloser, error) {
+	if isStdio(name) {
+		return io.NopCloser(s.stdin), nil
+	}
+
+	return os.Open(name)
+}
+
+// isStdio reports whether the file name refers to the
+// standard input or output.
+func isStdio(name string) bool {
+	return name == "" || name == "-"
+}
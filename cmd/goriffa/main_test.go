@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunWithoutCommand(t *testing.T) {
+	_, stderr, code := runCommand(t, nil)
+
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "usage: goriffa")
+}
+
+func TestRunHelp(t *testing.T) {
+	stdout, _, code := runCommand(t, nil, "help")
+
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, "dump")
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	_, stderr, code := runCommand(t, nil, "frobnicate")
+
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `unknown command "frobnicate"`)
+}
+
+func TestRunUnknownFlag(t *testing.T) {
+	_, stderr, code := runCommand(t, nil, "dump", "-frobnicate")
+
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "usage: goriffa dump")
+}
+
+func TestRunCommandHelp(t *testing.T) {
+	_, _, code := runCommand(t, nil, "dump", "-h")
+
+	assert.Equal(t, 0, code)
+}
+
+// runCommand will run goriffa with the provided standard
+// input and arguments, returning what was written to the
+// standard output and error along with the exit code.
+func runCommand(t *testing.T, stdin io.Reader, args ...string) (string, string, int) {
+	t.Helper()
+
+	if stdin == nil {
+		stdin = bytes.NewReader(nil)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run(args, streams{
+		stdin:  stdin,
+		stdout: &stdout,
+		stderr: &stderr,
+	})
+
+	return stdout.String(), stderr.String(), code
+}
@@ -0,0 +1,273 @@
+// Package codec provides a registry of chunk decoders and
+// encoders, allowing chunks to be read as typed values
+// rather than raw bytes - e.g. a wave.Format rather than
+// the data of a Wavefile's "fmt " chunk.
+//
+// Codecs are registered per file type and FOURCC, usually
+// by the package defining the chunk's type from its init
+// function; much like image formats, a package's codecs
+// are registered with Default by importing it:
+//  import _ "github.com/standoffvenus/goriffa/wave"
+//
+// Chunks no codec is registered for are left raw, i.e.
+// decoded as a goriffa.Chunk.
+package codec
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/internal"
+)
+
+var (
+	// ErrNoCodec is returned when a value is encoded as a
+	// chunk no codec is registered for.
+	ErrNoCodec error = errors.New("no codec registered")
+
+	// ErrUnsupportedValue is returned by encoders provided
+	// a value of a type they cannot encode.
+	ErrUnsupportedValue error = errors.New("unsupported value")
+)
+
+// AnyFileType may be passed to Register and RegisterList
+// to register a codec for chunks of every file type, such
+// as "INFO" lists. Codecs registered for a specific file
+// type take precedence.
+var AnyFileType internal.FileType
+
+// Default is the registry used by the package-level
+// functions.
+var Default = NewRegistry()
+
+// Codec decodes the data of a chunk into a typed value,
+// and encodes such values back into chunk data. The byte
+// order is that of the RIFF data (see reader.ByteOrder
+// and writer.ByteOrder).
+//
+// The data of a list chunk includes the list type.
+type Codec struct {
+	// Decode decodes the provided chunk data.
+	Decode func(data []byte, order binary.ByteOrder) (interface{}, error)
+
+	// Encode encodes the provided value into chunk data.
+	// If the value has a type Encode does not support,
+	// an error wrapping ErrUnsupportedValue should be
+	// returned.
+	Encode func(v interface{}, order binary.ByteOrder) ([]byte, error)
+}
+
+// Reader represents a RIFF data reader whose chunks can
+// be decoded, such as *reader.Reader.
+type Reader interface {
+	goriffa.Reader
+
+	// FileType returns the file type of the RIFF data.
+	FileType() internal.FileType
+
+	// ByteOrder returns the byte order of the RIFF data.
+	ByteOrder() binary.ByteOrder
+}
+
+// key identifies the chunks a codec is registered for.
+// For lists, identifier holds the list type.
+type key struct {
+	fileType   internal.FileType
+	identifier internal.FourCC
+	list       bool
+}
+
+// Registry holds registered codecs. The zero value is
+// NOT ready to use; use NewRegistry instead.
+//
+// Registry is concurrent-safe.
+type Registry struct {
+	mu     sync.RWMutex
+	codecs map[key]Codec
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{codecs: make(map[key]Codec)}
+}
+
+// Register registers the codec for chunks identified by
+// the provided FOURCC within RIFF data of the provided
+// file type (or AnyFileType). If the codec is incomplete,
+// or a codec is already registered for the same chunks,
+// Register panics.
+func (r *Registry) Register(fileType internal.FileType, identifier internal.FourCC, c Codec) {
+	r.register(key{fileType: fileType, identifier: identifier}, c)
+}
+
+// RegisterList registers the codec for "LIST" chunks of
+// the provided list type within RIFF data of the provided
+// file type (or AnyFileType). If the codec is incomplete,
+// or a codec is already registered for the same lists,
+// RegisterList panics.
+func (r *Registry) RegisterList(fileType internal.FileType, listType internal.FileType, c Codec) {
+	r.register(key{fileType: fileType, identifier: internal.FourCC(listType), list: true}, c)
+}
+
+// Decode will decode the provided chunk of RIFF data of
+// the provided file type, using the codec registered for
+// it. If there is no such codec, the chunk itself is
+// returned.
+func (r *Registry) Decode(fileType internal.FileType, c internal.Chunk, order binary.ByteOrder) (interface{}, error) {
+	k := key{fileType: fileType, identifier: c.Identifier}
+	if goriffa.IsList(c.Identifier) {
+		if len(c.Data) < 4 {
+			return c, nil
+		}
+		k = key{fileType: fileType, identifier: internal.FourCC(internal.Must4Byte(c.Data[:4])), list: true}
+	}
+
+	codec, ok := r.lookup(k)
+	if !ok {
+		return c, nil
+	}
+
+	v, err := codec.Decode(c.Data, order)
+	if err != nil {
+		return nil, fmt.Errorf("decoding chunk %q: %w", c.Identifier, err)
+	}
+
+	return v, nil
+}
+
+// Encode will encode the provided value as a chunk
+// identified by the provided FOURCC within RIFF data of
+// the provided file type. If v is a goriffa.Chunk, it's
+// returned as is. If no codec is registered for such
+// chunks, ErrNoCodec is returned.
+func (r *Registry) Encode(fileType internal.FileType, identifier internal.FourCC, v interface{}, order binary.ByteOrder) (internal.Chunk, error) {
+	return r.encode(key{fileType: fileType, identifier: identifier}, identifier, v, order)
+}
+
+// EncodeList will encode the provided value as a "LIST"
+// chunk of the provided list type within RIFF data of
+// the provided file type. If v is a goriffa.Chunk, it's
+// returned as is. If no codec is registered for such
+// lists, ErrNoCodec is returned.
+func (r *Registry) EncodeList(fileType internal.FileType, listType internal.FileType, v interface{}, order binary.ByteOrder) (internal.Chunk, error) {
+	return r.encode(key{fileType: fileType, identifier: internal.FourCC(listType), list: true}, goriffa.FourCCList, v, order)
+}
+
+// ReadAll will read every remaining chunk from the
+// provided Reader, decoding each one (see Decode) by the
+// Reader's file type and byte order. Lists are decoded
+// as a whole, so unless a codec is registered for a
+// list, it's returned as a raw chunk.
+//
+// As the FileType of a *reader.Reader returned by
+// ReadList is the list type, ReadAll should be provided
+// the top-level *reader.Reader.
+//
+// If an error occurs, the values decoded until then are
+// returned along with the error.
+func (r *Registry) ReadAll(rd Reader) ([]interface{}, error) {
+	var values []interface{}
+	for {
+		var c internal.Chunk
+		if _, err := rd.ReadChunk(&c); err != nil {
+			if errors.Is(err, io.EOF) {
+				return values, nil
+			}
+
+			return values, err
+		}
+
+		v, err := r.Decode(rd.FileType(), c, rd.ByteOrder())
+		if err != nil {
+			return values, err
+		}
+		values = append(values, v)
+	}
+}
+
+// Register registers the codec with Default, see
+// Registry.Register.
+func Register(fileType internal.FileType, identifier internal.FourCC, c Codec) {
+	Default.Register(fileType, identifier, c)
+}
+
+// RegisterList registers the codec with Default, see
+// Registry.RegisterList.
+func RegisterList(fileType internal.FileType, listType internal.FileType, c Codec) {
+	Default.RegisterList(fileType, listType, c)
+}
+
+// Decode decodes the chunk using Default, see
+// Registry.Decode.
+func Decode(fileType internal.FileType, c internal.Chunk, order binary.ByteOrder) (interface{}, error) {
+	return Default.Decode(fileType, c, order)
+}
+
+// Encode encodes the value using Default, see
+// Registry.Encode.
+func Encode(fileType internal.FileType, identifier internal.FourCC, v interface{}, order binary.ByteOrder) (internal.Chunk, error) {
+	return Default.Encode(fileType, identifier, v, order)
+}
+
+// EncodeList encodes the value using Default, see
+// Registry.EncodeList.
+func EncodeList(fileType internal.FileType, listType internal.FileType, v interface{}, order binary.ByteOrder) (internal.Chunk, error) {
+	return Default.EncodeList(fileType, listType, v, order)
+}
+
+// ReadAll reads the remaining chunks using Default, see
+// Registry.ReadAll.
+func ReadAll(rd Reader) ([]interface{}, error) {
+	return Default.ReadAll(rd)
+}
+
+func (r *Registry) register(k key, c Codec) {
+	if c.Decode == nil || c.Encode == nil {
+		internal.Panic(fmt.Sprintf("codec for %q is incomplete", k.identifier))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.codecs[k]; ok {
+		internal.Panic(fmt.Sprintf("codec for %q registered twice", k.identifier))
+	}
+	r.codecs[k] = c
+}
+
+// lookup returns the codec registered for the key,
+// falling back to the codec registered for any file type.
+func (r *Registry) lookup(k key) (Codec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if c, ok := r.codecs[k]; ok {
+		return c, true
+	}
+	k.fileType = AnyFileType
+	c, ok := r.codecs[k]
+
+	return c, ok
+}
+
+func (r *Registry) encode(k key, identifier internal.FourCC, v interface{}, order binary.ByteOrder) (internal.Chunk, error) {
+	if c, ok := v.(internal.Chunk); ok {
+		return c, nil
+	}
+
+	codec, ok := r.lookup(k)
+	if !ok {
+		return internal.Chunk{}, fmt.Errorf("%w: %q", ErrNoCodec, k.identifier)
+	}
+
+	data, err := codec.Encode(v, order)
+	if err != nil {
+		return internal.Chunk{}, fmt.Errorf("encoding chunk %q: %w", k.identifier, err)
+	}
+
+	return internal.Chunk{Identifier: identifier, Data: data}, nil
+}
@@ -0,0 +1,233 @@
+package codec_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/codec"
+	"github.com/standoffvenus/goriffa/info"
+	"github.com/standoffvenus/goriffa/internal"
+	"github.com/standoffvenus/goriffa/internal/test"
+	"github.com/standoffvenus/goriffa/reader"
+	"github.com/standoffvenus/goriffa/wave"
+	"github.com/standoffvenus/goriffa/writer"
+	"github.com/stretchr/testify/assert"
+)
+
+func Example() {
+	var buf bytes.Buffer
+	w, err := writer.NewBuffered(&buf, wave.FileTypeWavefile)
+	if err != nil {
+		panic(err)
+	}
+
+	format := wave.Format{AudioFormat: wave.PCM, Channels: 1, SampleRate: 8000, BitsPerSample: 8}
+	for _, c := range []struct {
+		id internal.FourCC
+		v  interface{}
+	}{
+		{goriffa.FourCCFormat, format},
+		{goriffa.FourCCData, goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{1, 2, 3}}},
+	} {
+		chunk, err := codec.Encode(wave.FileTypeWavefile, c.id, c.v, binary.LittleEndian)
+		if err != nil {
+			panic(err)
+		}
+		if _, err := w.WriteChunk(chunk); err != nil {
+			panic(err)
+		}
+	}
+	metadata, err := codec.EncodeList(wave.FileTypeWavefile, info.ListTypeInfo, info.Info{"INAM": "Beep"}, binary.LittleEndian)
+	if err != nil {
+		panic(err)
+	}
+	if _, err := w.WriteChunk(metadata); err != nil {
+		panic(err)
+	}
+	if err := w.Close(); err != nil {
+		panic(err)
+	}
+
+	r, err := reader.New(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		panic(err)
+	}
+	values, err := codec.ReadAll(r)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, v := range values {
+		switch v := v.(type) {
+		case wave.Format:
+			fmt.Printf("format: %dHz, %d-bit\n", v.SampleRate, v.BitsPerSample)
+		case info.Info:
+			fmt.Printf("title: %s\n", v["INAM"])
+		case goriffa.Chunk:
+			fmt.Printf("raw %q chunk: %d bytes\n", v.Identifier, len(v.Data))
+		}
+	}
+
+	// Output:
+	// format: 8000Hz, 8-bit
+	// raw "data" chunk: 3 bytes
+	// title: Beep
+}
+
+func TestRegistry(t *testing.T) {
+	registry := codec.NewRegistry()
+	registry.Register(test.FileType, fourCC("abcd"), upper)
+
+	c := goriffa.Chunk{Identifier: fourCC("abcd"), Data: []byte("data")}
+	v, err := registry.Decode(test.FileType, c, binary.LittleEndian)
+	assert.NoError(t, err)
+	assert.Equal(t, "DATA", v)
+
+	encoded, err := registry.Encode(test.FileType, fourCC("abcd"), "DATA", binary.LittleEndian)
+	assert.NoError(t, err)
+	assert.Equal(t, goriffa.Chunk{Identifier: fourCC("abcd"), Data: []byte("data")}, encoded)
+
+	// The codec is only registered for one file type.
+	v, err = registry.Decode(wave.FileTypeWavefile, c, binary.LittleEndian)
+	assert.NoError(t, err)
+	assert.Equal(t, c, v)
+}
+
+func TestRegistryUnknownChunk(t *testing.T) {
+	registry := codec.NewRegistry()
+
+	for _, c := range []goriffa.Chunk{
+		{Identifier: fourCC("abcd"), Data: []byte("data")},
+		{Identifier: goriffa.FourCCList, Data: []byte("INFO")},
+		{Identifier: goriffa.FourCCList, Data: []byte("IN")},
+	} {
+		v, err := registry.Decode(test.FileType, c, binary.LittleEndian)
+		assert.NoError(t, err)
+		assert.Equal(t, c, v)
+	}
+
+	_, err := registry.Encode(test.FileType, fourCC("abcd"), "DATA", binary.LittleEndian)
+	assert.ErrorIs(t, err, codec.ErrNoCodec)
+
+	// Raw chunks are encoded as they are.
+	c := goriffa.Chunk{Identifier: fourCC("abcd"), Data: []byte("data")}
+	encoded, err := registry.Encode(test.FileType, fourCC("abcd"), c, binary.LittleEndian)
+	assert.NoError(t, err)
+	assert.Equal(t, c, encoded)
+}
+
+func TestRegistryAnyFileType(t *testing.T) {
+	registry := codec.NewRegistry()
+	registry.Register(codec.AnyFileType, fourCC("abcd"), upper)
+	registry.Register(test.FileType, fourCC("abcd"), codec.Codec{
+		Decode: func([]byte, binary.ByteOrder) (interface{}, error) { return "specific", nil },
+		Encode: upper.Encode,
+	})
+
+	c := goriffa.Chunk{Identifier: fourCC("abcd"), Data: []byte("data")}
+	v, err := registry.Decode(wave.FileTypeWavefile, c, binary.LittleEndian)
+	assert.NoError(t, err)
+	assert.Equal(t, "DATA", v)
+
+	v, err = registry.Decode(test.FileType, c, binary.LittleEndian)
+	assert.NoError(t, err)
+	assert.Equal(t, "specific", v)
+}
+
+func TestRegistryList(t *testing.T) {
+	registry := codec.NewRegistry()
+	registry.RegisterList(test.FileType, internal.FileType(fourCC("abcd")), upper)
+
+	// Chunks sharing the list type's FOURCC are no lists.
+	c := goriffa.Chunk{Identifier: fourCC("abcd"), Data: []byte("data")}
+	v, err := registry.Decode(test.FileType, c, binary.LittleEndian)
+	assert.NoError(t, err)
+	assert.Equal(t, c, v)
+
+	v, err = registry.Decode(test.FileType, goriffa.Chunk{Identifier: goriffa.FourCCList, Data: []byte("abcdefgh")}, binary.LittleEndian)
+	assert.NoError(t, err)
+	assert.Equal(t, "ABCDEFGH", v)
+
+	encoded, err := registry.EncodeList(test.FileType, internal.FileType(fourCC("abcd")), "ABCDEFGH", binary.LittleEndian)
+	assert.NoError(t, err)
+	assert.Equal(t, goriffa.Chunk{Identifier: goriffa.FourCCList, Data: []byte("abcdefgh")}, encoded)
+
+	_, err = registry.Encode(test.FileType, fourCC("abcd"), "ABCD", binary.LittleEndian)
+	assert.ErrorIs(t, err, codec.ErrNoCodec)
+}
+
+func TestRegistryErrors(t *testing.T) {
+	registry := codec.NewRegistry()
+	registry.Register(test.FileType, fourCC("abcd"), upper)
+
+	_, err := registry.Decode(test.FileType, goriffa.Chunk{Identifier: fourCC("abcd"), Data: []byte("bad!")}, binary.LittleEndian)
+	assert.ErrorIs(t, err, goriffa.ErrBadChunk)
+
+	_, err = registry.Encode(test.FileType, fourCC("abcd"), 42, binary.LittleEndian)
+	assert.ErrorIs(t, err, codec.ErrUnsupportedValue)
+}
+
+func TestRegisterTwice(t *testing.T) {
+	registry := codec.NewRegistry()
+	registry.Register(test.FileType, fourCC("abcd"), upper)
+
+	assert.Panics(t, func() { registry.Register(test.FileType, fourCC("abcd"), upper) })
+	assert.Panics(t, func() { registry.Register(test.FileType, fourCC("efgh"), codec.Codec{}) })
+	assert.NotPanics(t, func() { registry.RegisterList(test.FileType, internal.FileType(fourCC("abcd")), upper) })
+}
+
+func TestReadAll(t *testing.T) {
+	wav, _ := test.WAV()
+	r, err := reader.New(wav)
+	assert.NoError(t, err)
+
+	values, err := codec.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Len(t, values, 3)
+
+	assert.Equal(t, wave.Format{
+		AudioFormat:   wave.PCM,
+		Channels:      2,
+		SampleRate:    44100,
+		BitsPerSample: 16,
+	}, values[0])
+	assert.Equal(t, fourCC("smpl"), values[1].(goriffa.Chunk).Identifier)
+	assert.Equal(t, goriffa.FourCCData, values[2].(goriffa.Chunk).Identifier)
+}
+
+func TestReadAllError(t *testing.T) {
+	data := []byte("RIFF\x14\x00\x00\x00WAVEfmt \x08\x00\x00\x00bad data")
+	r, err := reader.New(bytes.NewReader(data))
+	assert.NoError(t, err)
+
+	values, err := codec.ReadAll(r)
+	assert.ErrorIs(t, err, wave.ErrBadWaveData)
+	assert.Empty(t, values)
+}
+
+// upper decodes chunk data as upper-case strings, and
+// encodes them back into lower-case data.
+var upper = codec.Codec{
+	Decode: func(data []byte, _ binary.ByteOrder) (interface{}, error) {
+		if bytes.ContainsAny(data, "!") {
+			return nil, fmt.Errorf("%w: exclamation mark", goriffa.ErrBadChunk)
+		}
+
+		return string(bytes.ToUpper(data)), nil
+	},
+	Encode: func(v interface{}, _ binary.ByteOrder) ([]byte, error) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: %T", codec.ErrUnsupportedValue, v)
+		}
+
+		return bytes.ToLower([]byte(s)), nil
+	},
+}
+
+func fourCC(s string) internal.FourCC {
+	return internal.FourCC(internal.StringMust4Byte(s))
+}
@@ -21,6 +21,17 @@
 // "chunk length" holds the length of the preceding
 // "chunk" - the actual data of the chunk.
 //
+// Chunks identified by "LIST" (or a nested "RIFF") are
+// lists: their data begins with a 4-byte list type (e.g.
+// "INFO"), followed by sub-chunks in the format above.
+//
+// RIFF data is little-endian. Its big-endian variant
+// begins with "RIFX" instead.
+//
+// Files larger than 4 GB may be stored as RF64 (or BW64)
+// instead, which replace the "RIFF" identifier and store
+// the 64-bit sizes in a "ds64" chunk following the header.
+//
 // For more information, see Microsoft's overview
 // of RIFF: https://docs.microsoft.com/en-us/windows/win32/xaudio2/resource-interchange-file-format--riff-.
 package goriffa
@@ -34,6 +45,18 @@ type FileType = internal.FileType
 // Chunk represents a RIFF chunk.
 type Chunk = internal.Chunk
 
+// ChunkHeader represents the header of a RIFF chunk:
+// its identifier and the length of its data.
+type ChunkHeader = internal.ChunkHeader
+
+// DS64 represents the data of a "ds64" chunk, which
+// holds the 64-bit sizes of RF64 and BW64 files.
+type DS64 = internal.DS64
+
+// DS64Entry represents an entry of a "ds64" chunk's
+// table of chunk sizes.
+type DS64Entry = internal.DS64Entry
+
 type (
 	// Reader represents a RIFF data reader.
 	Reader interface {
@@ -63,8 +86,49 @@ type (
 // in a RIFF file.
 var (
 	FourCCRIFF   internal.FourCC = internal.FourCC(internal.StringMust4Byte("RIFF"))
+	FourCCRIFX   internal.FourCC = internal.FourCC(internal.StringMust4Byte("RIFX"))
+	FourCCRF64   internal.FourCC = internal.FourCC(internal.StringMust4Byte("RF64"))
+	FourCCBW64   internal.FourCC = internal.FourCC(internal.StringMust4Byte("BW64"))
+	FourCCDS64   internal.FourCC = internal.FourCC(internal.StringMust4Byte("ds64"))
+	FourCCList   internal.FourCC = internal.FourCC(internal.StringMust4Byte("LIST"))
+	FourCCJunk   internal.FourCC = internal.FourCC(internal.StringMust4Byte("JUNK"))
+	FourCCPad    internal.FourCC = internal.FourCC(internal.StringMust4Byte("PAD "))
+	FourCCFiller internal.FourCC = internal.FourCC(internal.StringMust4Byte("FLLR"))
 	FourCCFormat internal.FourCC = internal.FourCC(internal.StringMust4Byte("fmt "))
 	FourCCData   internal.FourCC = internal.FourCC(internal.StringMust4Byte("data"))
 	FourCCSMPL   internal.FourCC = internal.FourCC(internal.StringMust4Byte("smpl"))
 	FourCCWSMP   internal.FourCC = internal.FourCC(internal.StringMust4Byte("wsmp"))
 )
+
+// IsList reports whether the provided FOURCC identifies
+// a chunk that holds other chunks - i.e. "LIST" chunks
+// and nested "RIFF" forms. The data of such a chunk
+// begins with a 4-byte list type (e.g. "INFO"), followed
+// by the list's sub-chunks.
+func IsList(id internal.FourCC) bool {
+	return id == FourCCList || id == FourCCRIFF
+}
+
+// IsFiller reports whether the provided FOURCC identifies
+// a filler chunk - i.e. "JUNK", "PAD " and "FLLR" chunks -
+// whose data is meaningless and only takes up space.
+func IsFiller(id internal.FourCC) bool {
+	return id == FourCCJunk || id == FourCCPad || id == FourCCFiller
+}
+
+// IsPrintable reports whether the provided FOURCC is made
+// up of printable ASCII characters, as every FOURCC should
+// be: letters, digits, punctuation and spaces - though a
+// FOURCC may not begin with a space.
+func IsPrintable(id internal.FourCC) bool {
+	if id[0] == ' ' {
+		return false
+	}
+	for _, c := range id {
+		if c < ' ' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
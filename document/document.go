@@ -0,0 +1,585 @@
+// Package document provides an in-memory model of RIFF
+// data: a Document holds the file type and the tree of
+// chunks and lists, which may be edited freely before
+// the Document is encoded again.
+//
+// A Document may either hold the data of every chunk
+// (see Decode) or, for RIFF data too large to be held in
+// memory, only the chunk headers (see Open), loading the
+// data of chunks on demand.
+package document
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/internal"
+	"github.com/standoffvenus/goriffa/reader"
+	"github.com/standoffvenus/goriffa/writer"
+)
+
+// ErrOutOfRange is returned when a chunk is edited at a
+// position outside of the list it belongs to.
+var ErrOutOfRange error = errors.New("position out of range")
+
+var errNotList error = fmt.Errorf("%w: chunk is not a list", internal.ErrBadChunk)
+
+// Document represents RIFF data in its entirety.
+//
+// A Document is NOT concurrent-safe.
+type Document struct {
+	// Identifier holds the FOURCC the RIFF data begins
+	// with: "RIFF", "RIFX", "RF64" or "BW64".
+	Identifier internal.FourCC
+
+	// FileType holds the file type of the RIFF data,
+	// e.g. "WAVE".
+	FileType internal.FileType
+
+	// Chunks holds the top-level chunks.
+	Chunks []*Node
+}
+
+// Node represents a chunk of a Document. A Node either
+// holds data or - if it's a list (see goriffa.IsList) -
+// sub-chunks.
+type Node struct {
+	// Identifier holds the chunk's FOURCC.
+	Identifier internal.FourCC
+
+	// ListType holds the list type of lists.
+	ListType internal.FileType
+
+	// Data holds the data of chunks that aren't lists.
+	// For a chunk of a Document returned by Open, Data
+	// is nil until the data is loaded by ReadData; use
+	// SetData to replace the data of such a chunk.
+	Data []byte
+
+	// Chunks holds the sub-chunks of lists.
+	Chunks []*Node
+
+	// src holds the RIFF data the chunk's data is read
+	// from - starting at offset - until it's loaded.
+	src    io.ReaderAt
+	offset int64
+	length int64
+}
+
+// New creates an empty Document of the provided file
+// type, holding "RIFF" data.
+func New(fileType internal.FileType) *Document {
+	return &Document{Identifier: goriffa.FourCCRIFF, FileType: fileType}
+}
+
+// NewChunk creates a Node holding the provided data.
+func NewChunk(identifier internal.FourCC, data []byte) *Node {
+	return &Node{Identifier: identifier, Data: data}
+}
+
+// NewList creates a "LIST" Node of the provided list type
+// holding the provided sub-chunks.
+func NewList(listType internal.FileType, chunks ...*Node) *Node {
+	return &Node{Identifier: goriffa.FourCCList, ListType: listType, Chunks: chunks}
+}
+
+// Decode will read the remaining chunks of the provided
+// top-level Reader - descending into lists - into a new
+// Document. If an error occurs, it's returned along with
+// the Document read until then.
+func Decode(r *reader.Reader) (*Document, error) {
+	d := &Document{Identifier: r.Identifier(), FileType: r.FileType()}
+
+	chunks, err := decodeChunks(r)
+	d.Chunks = chunks
+
+	return d, err
+}
+
+// Open will index the RIFF data held by the provided
+// io.ReaderAt (see reader.NewIndex, which the options
+// are passed to) into a new Document holding no chunk
+// data: the data of a chunk is read from r once needed,
+// i.e. when ReadData (or Open) is called or when the
+// Document is encoded. Chunks whose data is left
+// untouched are streamed from r when encoding, so a
+// Document of any size may be edited and encoded again
+// while holding no more than the chunk headers - and the
+// data of the edited chunks - in memory.
+//
+// r must not be modified while the Document is in use,
+// so an edited Document may not be encoded to the same
+// file r reads from.
+func Open(r io.ReaderAt, options ...reader.Option) (*Document, error) {
+	idx, err := reader.NewIndex(r, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Document{Identifier: idx.Identifier(), FileType: idx.FileType()}
+	entries := idx.Entries()
+	nodes := make([]*Node, len(entries))
+	for i, e := range entries {
+		n := &Node{Identifier: e.Identifier, ListType: e.ListType}
+		if !n.IsList() {
+			n.src = r
+			n.offset = e.Offset + int64(internal.LengthChunkHeader)
+			n.length = e.Size
+		}
+		nodes[i] = n
+
+		// Lists precede their sub-chunks.
+		if e.Parent < 0 {
+			d.Chunks = append(d.Chunks, n)
+		} else {
+			nodes[e.Parent].Chunks = append(nodes[e.Parent].Chunks, n)
+		}
+	}
+
+	return d, nil
+}
+
+// Encode will write the Document to the provided writer,
+// through a writer.Writer. A Document decoded from RIFF
+// or RIFX data is encoded byte-identically unless it was
+// edited, provided the data was well-formed: i.e. the
+// RIFF size matched the chunks, padding bytes were 0 and
+// no data followed the RIFF data. RF64 and BW64 data is
+// encoded via writer.PromoteToRF64, so it's only encoded
+// as RF64 data if it's larger than 4 GB. Unless w
+// implements writer.WriterWithWriterAt, the RF64 header
+// and "ds64" chunk are written up front instead, as the
+// sizes of all chunks are known; either way, the data is
+// streamed.
+func (d *Document) Encode(w io.Writer) error {
+	var size int64
+	for _, n := range d.Chunks {
+		size += n.ByteLength()
+	}
+
+	var (
+		rw  *writer.Writer
+		err error
+	)
+	switch d.Identifier {
+	case goriffa.FourCCRIFF:
+		rw, err = writer.NewSized(w, d.FileType, size)
+	case goriffa.FourCCRIFX:
+		rw, err = writer.NewSized(w, d.FileType, size, writer.ByteOrder(binary.BigEndian))
+	case goriffa.FourCCRF64, goriffa.FourCCBW64:
+		if _, ok := w.(writer.WriterWithWriterAt); !ok && rf64Size(size) > math.MaxUint32 {
+			return encodeRF64(w, d.FileType, d.Chunks, size)
+		}
+		rw, err = newRF64Writer(w, d.FileType, size)
+	default:
+		return fmt.Errorf("%w: unsupported identifier %q", internal.ErrCorrupted, d.Identifier)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := encodeChunks(rw, d.Chunks, d.byteOrder()); err != nil {
+		return err
+	}
+
+	return rw.Close()
+}
+
+// newRF64Writer will create a writer promoting the RIFF
+// data - holding chunks of the provided total byte length
+// - to RF64 data if it's larger than 4 GB. Unless w
+// implements writer.WriterWithWriterAt, the data must not
+// be larger than 4 GB; see encodeRF64.
+func newRF64Writer(w io.Writer, fileType internal.FileType, size int64) (*writer.Writer, error) {
+	if at, ok := w.(writer.WriterWithWriterAt); ok {
+		return writer.New(at, fileType, writer.PromoteToRF64())
+	}
+
+	// The writer reserves room for the ds64 chunk via a
+	// "JUNK" chunk, which is only rewritten for RF64 data:
+	// smaller data may be written the same way, with no
+	// need to back-patch.
+	reserved := internal.Chunk{Identifier: goriffa.FourCCJunk, Data: make([]byte, internal.LengthDS64)}
+	rw, err := writer.NewSized(w, fileType, size+reserved.ByteLength())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := rw.WriteChunk(reserved); err != nil {
+		return nil, err
+	}
+
+	return rw, nil
+}
+
+// encodeRF64 will write RF64 data holding the provided
+// chunks - of the provided total byte length - to w. As
+// the sizes of all chunks are known, the RF64 header and
+// "ds64" chunk are written up front, after which the
+// chunks are streamed. Just like writer.PromoteToRF64,
+// only the "data" chunk may be larger than 4 GB.
+func encodeRF64(w io.Writer, fileType internal.FileType, nodes []*Node, size int64) error {
+	ds64 := internal.DS64{RIFFSize: uint64(rf64Size(size))}
+	for _, n := range nodes {
+		if n.Identifier == goriffa.FourCCData {
+			ds64.DataSize = uint64(n.size())
+		}
+	}
+
+	if _, err := internal.Write(w,
+		goriffa.FourCCRF64[:],
+		internal.LittleEndianUInt32Bytes(math.MaxUint32),
+		fileType[:],
+		goriffa.FourCCDS64[:],
+		internal.LittleEndianUInt32Bytes(uint32(internal.LengthDS64)),
+		ds64.Bytes(),
+	); err != nil {
+		return err
+	}
+
+	return writeRaw(w, nodes, binary.LittleEndian)
+}
+
+// rf64Size returns the RIFF size of data holding chunks
+// of the provided total byte length, along with the file
+// type and the "ds64" chunk (or the "JUNK" chunk
+// reserving its room).
+func rf64Size(size int64) int64 {
+	return int64(len(internal.FileType{})+internal.LengthChunkHeader+internal.LengthDS64) + size
+}
+
+// Insert will insert the provided chunk among the
+// top-level chunks, at the provided position.
+func (d *Document) Insert(i int, n *Node) error {
+	return insert(&d.Chunks, i, n)
+}
+
+// Remove will remove and return the top-level chunk at
+// the provided position.
+func (d *Document) Remove(i int) (*Node, error) {
+	return remove(&d.Chunks, i)
+}
+
+// Move will move the top-level chunk at position from to
+// position to, shifting the chunks in between.
+func (d *Document) Move(from, to int) error {
+	return move(d.Chunks, from, to)
+}
+
+// IsList reports whether the Node is a list.
+func (n *Node) IsList() bool {
+	return goriffa.IsList(n.Identifier)
+}
+
+// ByteLength returns the byte length of the chunk once
+// encoded, including its header and padding.
+func (n *Node) ByteLength() int64 {
+	return internal.ChunkHeader{Size: n.size()}.ByteLength()
+}
+
+// Insert will insert the provided chunk among the list's
+// sub-chunks, at the provided position. If the Node isn't
+// a list, goriffa.ErrBadChunk is returned.
+func (n *Node) Insert(i int, c *Node) error {
+	if !n.IsList() {
+		return errNotList
+	}
+
+	return insert(&n.Chunks, i, c)
+}
+
+// Remove will remove and return the list's sub-chunk at
+// the provided position. If the Node isn't a list,
+// goriffa.ErrBadChunk is returned.
+func (n *Node) Remove(i int) (*Node, error) {
+	if !n.IsList() {
+		return nil, errNotList
+	}
+
+	return remove(&n.Chunks, i)
+}
+
+// Move will move the list's sub-chunk at position from
+// to position to, shifting the sub-chunks in between. If
+// the Node isn't a list, goriffa.ErrBadChunk is returned.
+func (n *Node) Move(from, to int) error {
+	if !n.IsList() {
+		return errNotList
+	}
+
+	return move(n.Chunks, from, to)
+}
+
+// SetData will replace the data of the chunk. If the Node
+// is a list, goriffa.ErrBadChunk is returned.
+func (n *Node) SetData(data []byte) error {
+	if n.IsList() {
+		return errHoldsNoData(n)
+	}
+	n.Data = data
+	n.src = nil
+
+	return nil
+}
+
+// ReadData returns the data of the chunk, reading it from
+// the RIFF data first if it's not loaded yet (i.e. if the
+// chunk belongs to a Document returned by Open).
+// If the Node is a list, goriffa.ErrBadChunk is returned.
+func (n *Node) ReadData() ([]byte, error) {
+	if n.IsList() {
+		return nil, errHoldsNoData(n)
+	}
+	if n.src == nil {
+		return n.Data, nil
+	}
+
+	// The buffer grows as the data is read, as the chunk's
+	// size may claim far more data than there is.
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, n.Open(), n.length); errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: chunk %q is cut short", internal.ErrCorrupted, n.Identifier)
+	} else if err != nil {
+		return nil, err
+	}
+	n.Data = buf.Bytes()
+	n.src = nil
+
+	return n.Data, nil
+}
+
+// Open returns a reader of the data of the chunk. If the
+// chunk belongs to a Document returned by Open and its
+// data is not loaded yet, the data is streamed from the
+// RIFF data rather than loaded. Lists hold no data, so
+// for lists, the reader reads nothing.
+func (n *Node) Open() io.Reader {
+	if n.src != nil {
+		return io.NewSectionReader(n.src, n.offset, n.length)
+	}
+
+	return bytes.NewReader(n.Data)
+}
+
+// size returns the size of the chunk's data, which for
+// lists includes the list type.
+func (n *Node) size() int64 {
+	if !n.IsList() {
+		if n.src != nil {
+			return n.length
+		}
+
+		return int64(len(n.Data))
+	}
+
+	size := int64(len(n.ListType))
+	for _, c := range n.Chunks {
+		size += c.ByteLength()
+	}
+
+	return size
+}
+
+func (d *Document) byteOrder() binary.ByteOrder {
+	if d.Identifier == goriffa.FourCCRIFX {
+		return binary.BigEndian
+	}
+
+	return binary.LittleEndian
+}
+
+func decodeChunks(r *reader.Reader) ([]*Node, error) {
+	var nodes []*Node
+	for {
+		header, err := r.NextHeader()
+		if errors.Is(err, io.EOF) {
+			return nodes, nil
+		} else if err != nil {
+			return nodes, err
+		}
+
+		if !goriffa.IsList(header.Identifier) {
+			var c internal.Chunk
+			if _, err := r.ReadChunk(&c); err != nil {
+				return nodes, err
+			}
+			nodes = append(nodes, NewChunk(c.Identifier, c.Data))
+
+			continue
+		}
+
+		list, err := r.ReadList()
+		if err != nil {
+			return nodes, err
+		}
+
+		n := &Node{Identifier: header.Identifier, ListType: list.FileType()}
+		nodes = append(nodes, n)
+		if n.Chunks, err = decodeChunks(list); err != nil {
+			return nodes, err
+		}
+	}
+}
+
+func encodeChunks(w *writer.Writer, nodes []*Node, order binary.ByteOrder) error {
+	for _, n := range nodes {
+		switch {
+		case n.src != nil:
+			cw, err := w.BeginSizedChunk(n.Identifier, n.length)
+			if err != nil {
+				return err
+			}
+			if err := copyData(cw, n); err != nil {
+				return err
+			}
+			if err := cw.Close(); err != nil {
+				return err
+			}
+		case !n.IsList():
+			if _, err := w.WriteChunk(internal.Chunk{Identifier: n.Identifier, Data: n.Data}); err != nil {
+				return err
+			}
+		case n.Identifier == goriffa.FourCCList:
+			list, err := w.CreateSizedList(n.ListType, n.size()-int64(len(n.ListType)))
+			if err != nil {
+				return err
+			}
+			if err := encodeChunks(list, n.Chunks, order); err != nil {
+				return err
+			}
+			if err := list.Close(); err != nil {
+				return err
+			}
+		default:
+			// Writers only create "LIST" lists, so any other
+			// list (i.e. a nested "RIFF" form) is written as
+			// a chunk holding the encoded list.
+			cw, err := w.BeginSizedChunk(n.Identifier, n.size())
+			if err != nil {
+				return err
+			}
+			if _, err := cw.Write(n.ListType[:]); err != nil {
+				return err
+			}
+			if err := writeRaw(cw, n.Chunks, order); err != nil {
+				return err
+			}
+			if err := cw.Close(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeRaw will write the provided chunks to w, without
+// a writer.Writer. The size of a "data" chunk larger than
+// 4 GB is written as 0xFFFFFFFF, as held by the "ds64"
+// chunk of RF64 data instead.
+func writeRaw(w io.Writer, nodes []*Node, order binary.ByteOrder) error {
+	for _, n := range nodes {
+		size := n.size()
+		switch {
+		case size <= math.MaxUint32:
+		case n.Identifier == goriffa.FourCCData:
+			size = math.MaxUint32
+		default:
+			return fmt.Errorf("%w: chunk %q too large - size overflow", internal.ErrCorrupted, n.Identifier)
+		}
+
+		var header [internal.LengthChunkHeader]byte
+		copy(header[:], n.Identifier[:])
+		order.PutUint32(header[4:], uint32(size))
+		if _, err := internal.Write(w, header[:]); err != nil {
+			return err
+		}
+
+		if n.IsList() {
+			if _, err := w.Write(n.ListType[:]); err != nil {
+				return err
+			}
+			if err := writeRaw(w, n.Chunks, order); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := copyData(w, n); err != nil {
+			return err
+		}
+		if n.size()%2 != 0 {
+			if _, err := w.Write([]byte{0}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// copyData will copy the data of the chunk to w. If the
+// data is read from the RIFF data and turns out to be
+// shorter than the chunk, goriffa.ErrCorrupted is
+// returned.
+func copyData(w io.Writer, n *Node) error {
+	copied, err := io.Copy(w, n.Open())
+	if err != nil {
+		return err
+	}
+	if copied < n.size() {
+		return fmt.Errorf("%w: chunk %q is cut short", internal.ErrCorrupted, n.Identifier)
+	}
+
+	return nil
+}
+
+func errHoldsNoData(n *Node) error {
+	return fmt.Errorf("%w: list %q holds no data", internal.ErrBadChunk, n.Identifier)
+}
+
+func insert(nodes *[]*Node, i int, n *Node) error {
+	if i < 0 || i > len(*nodes) {
+		return fmt.Errorf("%w: cannot insert at %d of %d chunks", ErrOutOfRange, i, len(*nodes))
+	}
+
+	*nodes = append(*nodes, nil)
+	copy((*nodes)[i+1:], (*nodes)[i:])
+	(*nodes)[i] = n
+
+	return nil
+}
+
+func remove(nodes *[]*Node, i int) (*Node, error) {
+	if i < 0 || i >= len(*nodes) {
+		return nil, fmt.Errorf("%w: cannot remove %d of %d chunks", ErrOutOfRange, i, len(*nodes))
+	}
+
+	n := (*nodes)[i]
+	copy((*nodes)[i:], (*nodes)[i+1:])
+	(*nodes)[len(*nodes)-1] = nil
+	*nodes = (*nodes)[:len(*nodes)-1]
+
+	return n, nil
+}
+
+func move(nodes []*Node, from, to int) error {
+	if from < 0 || from >= len(nodes) || to < 0 || to >= len(nodes) {
+		return fmt.Errorf("%w: cannot move %d to %d of %d chunks", ErrOutOfRange, from, to, len(nodes))
+	}
+
+	n := nodes[from]
+	if from < to {
+		copy(nodes[from:], nodes[from+1:to+1])
+	} else {
+		copy(nodes[to+1:], nodes[to:from])
+	}
+	nodes[to] = n
+
+	return nil
+}
@@ -0,0 +1,311 @@
+package document_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/document"
+	"github.com/standoffvenus/goriffa/internal"
+	"github.com/standoffvenus/goriffa/internal/test"
+	"github.com/standoffvenus/goriffa/reader"
+	"github.com/stretchr/testify/assert"
+)
+
+func Example() {
+	d := document.New(internal.Must4Byte([]byte("WAVE")))
+	d.Chunks = append(d.Chunks,
+		document.NewChunk(goriffa.FourCCData, []byte{1, 2, 3}),
+		document.NewList(internal.Must4Byte([]byte("INFO")),
+			document.NewChunk(fourCC("INAM"), []byte("Song\x00"))))
+
+	// Move the metadata to the front.
+	if err := d.Move(1, 0); err != nil {
+		panic(err)
+	}
+
+	var buf bytes.Buffer
+	if err := d.Encode(&buf); err != nil {
+		panic(err)
+	}
+
+	r, err := reader.New(&buf)
+	if err != nil {
+		panic(err)
+	}
+	decoded, err := document.Decode(r)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, n := range decoded.Chunks {
+		if n.IsList() {
+			fmt.Printf("%q list of %d chunks\n", n.ListType, len(n.Chunks))
+		} else {
+			fmt.Printf("%q chunk of %d bytes\n", n.Identifier, len(n.Data))
+		}
+	}
+
+	// Output:
+	// "INFO" list of 1 chunks
+	// "data" chunk of 3 bytes
+}
+
+func TestRoundTrip(t *testing.T) {
+	wav, _ := test.WAV()
+	webp, _ := test.WEBP()
+
+	for name, r := range map[string]io.Reader{
+		"WAV":    wav,
+		"WEBP":   webp,
+		"nested": bytes.NewReader(nestedRIFF(binary.LittleEndian)),
+		"RIFX":   bytes.NewReader(nestedRIFF(binary.BigEndian)),
+	} {
+		original, err := io.ReadAll(r)
+		assert.NoError(t, err, name)
+
+		d := decode(t, original)
+		assert.Equal(t, original, encode(t, d), name)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	d := decode(t, nestedRIFF(binary.BigEndian))
+
+	assert.Equal(t, goriffa.FourCCRIFX, d.Identifier)
+	assert.Equal(t, test.FileType, d.FileType)
+	assert.Equal(t, []*document.Node{
+		document.NewChunk(fourCC("abcd"), []byte("odd")),
+		document.NewList(internal.Must4Byte([]byte("INFO")),
+			document.NewChunk(fourCC("INAM"), []byte("name")),
+			document.NewList(internal.Must4Byte([]byte("sub ")))),
+		{
+			Identifier: goriffa.FourCCRIFF,
+			ListType:   internal.Must4Byte([]byte("AVIX")),
+			Chunks:     []*document.Node{document.NewChunk(fourCC("movi"), []byte("x"))},
+		},
+	}, d.Chunks)
+}
+
+func TestDecodeCorrupted(t *testing.T) {
+	data := nestedRIFF(binary.LittleEndian)
+
+	r, err := reader.New(bytes.NewReader(data[:len(data)-4]))
+	assert.NoError(t, err)
+	d, err := document.Decode(r)
+
+	assert.ErrorIs(t, err, goriffa.ErrCorrupted)
+	assert.Len(t, d.Chunks, 3)
+}
+
+func TestDecodeImpossibleSize(t *testing.T) {
+	// The size of the "data" chunk outlasts the RIFF size,
+	// or both claim far more data than there is.
+	for _, riffSize := range []uint64{52, 1<<62 + 40} {
+		var ds64 []byte
+		ds64 = append(ds64, internal.LittleEndianUInt64Bytes(riffSize)...)
+		ds64 = append(ds64, internal.LittleEndianUInt64Bytes(1<<62)...)
+		ds64 = append(ds64, make([]byte, 12)...)
+
+		var buf bytes.Buffer
+		buf.Write(goriffa.FourCCRF64[:])
+		buf.Write(internal.LittleEndianUInt32Bytes(0xFFFFFFFF))
+		buf.Write(test.FileType[:])
+		buf.Write(chunk(binary.LittleEndian, "ds64", ds64))
+		buf.Write(goriffa.FourCCData[:])
+		buf.Write(internal.LittleEndianUInt32Bytes(0xFFFFFFFF))
+		buf.Write([]byte{1, 2, 3, 4})
+
+		r, err := reader.New(bytes.NewReader(buf.Bytes()))
+		assert.NoError(t, err)
+		_, err = document.Decode(r)
+		assert.ErrorIs(t, err, goriffa.ErrCorrupted)
+
+		d, err := document.Open(bytes.NewReader(buf.Bytes()))
+		if err == nil {
+			_, err = d.Chunks[0].ReadData()
+		}
+		assert.ErrorIs(t, err, goriffa.ErrCorrupted)
+	}
+}
+
+func TestEdit(t *testing.T) {
+	d := decode(t, nestedRIFF(binary.LittleEndian))
+
+	assert.NoError(t, d.Chunks[0].SetData([]byte("even")))
+	info := d.Chunks[1]
+	assert.NoError(t, info.Insert(0, document.NewChunk(fourCC("ICMT"), []byte("comment"))))
+	removed, err := info.Remove(2)
+	assert.NoError(t, err)
+	assert.Equal(t, internal.FileType(internal.Must4Byte([]byte("sub "))), removed.ListType)
+	assert.NoError(t, info.Move(0, 1))
+	_, err = d.Remove(2)
+	assert.NoError(t, err)
+	assert.NoError(t, d.Insert(2, document.NewChunk(goriffa.FourCCData, nil)))
+
+	r, err := reader.New(bytes.NewReader(encode(t, d)))
+	assert.NoError(t, err)
+	paths := make(map[string]string)
+	assert.NoError(t, reader.Walk(r, func(path string, _ goriffa.ChunkHeader, data io.Reader) error {
+		if data != nil {
+			b, err := io.ReadAll(data)
+			paths[path] = string(b)
+
+			return err
+		}
+		paths[path] = ""
+
+		return nil
+	}))
+
+	assert.Equal(t, map[string]string{
+		"abcd":            "even",
+		"LIST[INFO]":      "",
+		"LIST[INFO]/INAM": "name",
+		"LIST[INFO]/ICMT": "comment",
+		"data":            "",
+	}, paths)
+}
+
+func TestEditOutOfRange(t *testing.T) {
+	d := decode(t, nestedRIFF(binary.LittleEndian))
+
+	assert.ErrorIs(t, d.Insert(4, document.NewChunk(fourCC("abcd"), nil)), document.ErrOutOfRange)
+	assert.ErrorIs(t, d.Insert(-1, document.NewChunk(fourCC("abcd"), nil)), document.ErrOutOfRange)
+	_, err := d.Remove(3)
+	assert.ErrorIs(t, err, document.ErrOutOfRange)
+	assert.ErrorIs(t, d.Move(0, 3), document.ErrOutOfRange)
+	assert.ErrorIs(t, d.Move(-1, 0), document.ErrOutOfRange)
+	assert.Len(t, d.Chunks, 3)
+}
+
+func TestMove(t *testing.T) {
+	d := document.New(test.FileType)
+	for _, id := range []string{"0000", "1111", "2222", "3333"} {
+		d.Chunks = append(d.Chunks, document.NewChunk(fourCC(id), nil))
+	}
+
+	assert.NoError(t, d.Move(0, 2))
+	assert.Equal(t, "1111 2222 0000 3333", identifiers(d.Chunks))
+	assert.NoError(t, d.Move(3, 1))
+	assert.Equal(t, "1111 3333 2222 0000", identifiers(d.Chunks))
+	assert.NoError(t, d.Move(2, 2))
+	assert.Equal(t, "1111 3333 2222 0000", identifiers(d.Chunks))
+}
+
+func TestEditNotList(t *testing.T) {
+	chunk := document.NewChunk(fourCC("abcd"), nil)
+	list := document.NewList(internal.Must4Byte([]byte("INFO")))
+
+	assert.ErrorIs(t, chunk.Insert(0, list), goriffa.ErrBadChunk)
+	_, err := chunk.Remove(0)
+	assert.ErrorIs(t, err, goriffa.ErrBadChunk)
+	assert.ErrorIs(t, chunk.Move(0, 0), goriffa.ErrBadChunk)
+	assert.ErrorIs(t, list.SetData([]byte("data")), goriffa.ErrBadChunk)
+}
+
+func TestByteLength(t *testing.T) {
+	assert.Equal(t, int64(12), document.NewChunk(fourCC("abcd"), []byte("odd")).ByteLength())
+	assert.Equal(t, int64(12+12+8), document.NewList(internal.Must4Byte([]byte("INFO")),
+		document.NewChunk(fourCC("abcd"), []byte("odd")),
+		document.NewChunk(fourCC("efgh"), nil)).ByteLength())
+}
+
+func TestEncodeRF64(t *testing.T) {
+	d := decode(t, nestedRIFF(binary.LittleEndian))
+	d.Identifier = goriffa.FourCCRF64
+
+	// Small enough to be encoded as RIFF data, with room
+	// reserved for a "ds64" chunk.
+	b := encode(t, d)
+	assert.Equal(t, goriffa.FourCCRIFF[:], b[:4])
+	assert.Equal(t, goriffa.FourCCJunk[:], b[12:16])
+	assert.Equal(t, nestedRIFF(binary.LittleEndian)[12:], b[48:])
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "rf64.riff"))
+	assert.NoError(t, err)
+	defer f.Close()
+	assert.NoError(t, d.Encode(f))
+
+	written, err := os.ReadFile(f.Name())
+	assert.NoError(t, err)
+	assert.Equal(t, b, written)
+
+	r, err := reader.New(bytes.NewReader(b))
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(len(b)-8), r.Size())
+}
+
+func TestEncodeUnsupported(t *testing.T) {
+	d := document.New(test.FileType)
+	d.Identifier = fourCC("abcd")
+
+	assert.ErrorIs(t, d.Encode(io.Discard), goriffa.ErrCorrupted)
+}
+
+func decode(t *testing.T, b []byte) *document.Document {
+	r, err := reader.New(bytes.NewReader(b))
+	assert.NoError(t, err)
+	d, err := document.Decode(r)
+	assert.NoError(t, err)
+
+	return d
+}
+
+func encode(t *testing.T, d *document.Document) []byte {
+	var buf bytes.Buffer
+	assert.NoError(t, d.Encode(&buf))
+
+	return buf.Bytes()
+}
+
+func identifiers(nodes []*document.Node) string {
+	var buf bytes.Buffer
+	for i, n := range nodes {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.Write(n.Identifier[:])
+	}
+
+	return buf.String()
+}
+
+// nestedRIFF returns RIFF data - or RIFX data, if the
+// byte order is big-endian - holding nested lists,
+// including a nested "RIFF" form.
+func nestedRIFF(order binary.ByteOrder) []byte {
+	content := bytes.Join([][]byte{
+		chunk(order, "abcd", []byte("odd")),
+		chunk(order, "LIST", bytes.Join([][]byte{
+			[]byte("INFO"),
+			chunk(order, "INAM", []byte("name")),
+			chunk(order, "LIST", []byte("sub ")),
+		}, nil)),
+		chunk(order, "RIFF", append([]byte("AVIX"), chunk(order, "movi", []byte("x"))...)),
+	}, nil)
+
+	prefix := "RIFF"
+	if order == binary.BigEndian {
+		prefix = "RIFX"
+	}
+
+	return chunk(order, prefix, append(test.FileType[:], content...))
+}
+
+func chunk(order binary.ByteOrder, identifier string, data []byte) []byte {
+	b := make([]byte, internal.LengthChunkHeader)
+	copy(b, identifier)
+	order.PutUint32(b[4:], uint32(len(data)))
+
+	return append(b, internal.Pad(data)...)
+}
+
+func fourCC(s string) internal.FourCC {
+	return internal.FourCC(internal.StringMust4Byte(s))
+}
@@ -0,0 +1,286 @@
+package document_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/document"
+	"github.com/standoffvenus/goriffa/internal"
+	"github.com/standoffvenus/goriffa/internal/test"
+	"github.com/standoffvenus/goriffa/reader"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpen(t *testing.T) {
+	data := nestedRIFF(binary.BigEndian)
+	src := &countingReaderAt{r: bytes.NewReader(data)}
+
+	d, err := document.Open(src)
+	assert.NoError(t, err)
+	headers := src.n
+
+	assert.Equal(t, goriffa.FourCCRIFX, d.Identifier)
+	assert.Equal(t, test.FileType, d.FileType)
+	assert.Equal(t, "abcd LIST RIFF", identifiers(d.Chunks))
+	assert.Equal(t, "INAM LIST", identifiers(d.Chunks[1].Chunks))
+	assert.Equal(t, internal.FileType(internal.Must4Byte([]byte("AVIX"))), d.Chunks[2].ListType)
+
+	// No data is loaded up front.
+	assert.Nil(t, d.Chunks[0].Data)
+	assert.Equal(t, int64(12), d.Chunks[0].ByteLength())
+
+	b, err := d.Chunks[0].ReadData()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("odd"), b)
+	assert.Equal(t, []byte("odd"), d.Chunks[0].Data)
+	assert.Equal(t, headers+3, src.n)
+
+	// Once loaded, the data is read from memory.
+	b, err = io.ReadAll(d.Chunks[0].Open())
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("odd"), b)
+	assert.Equal(t, headers+3, src.n)
+
+	_, err = d.Chunks[1].ReadData()
+	assert.ErrorIs(t, err, goriffa.ErrBadChunk)
+}
+
+func TestOpenRoundTrip(t *testing.T) {
+	wav, _ := test.WAV()
+	webp, _ := test.WEBP()
+
+	for name, r := range map[string]io.Reader{
+		"WAV":    wav,
+		"WEBP":   webp,
+		"nested": bytes.NewReader(nestedRIFF(binary.LittleEndian)),
+		"RIFX":   bytes.NewReader(nestedRIFF(binary.BigEndian)),
+	} {
+		original, err := io.ReadAll(r)
+		assert.NoError(t, err, name)
+
+		d, err := document.Open(bytes.NewReader(original))
+		assert.NoError(t, err, name)
+		assert.Equal(t, original, encode(t, d), name)
+	}
+}
+
+func TestOpenEdit(t *testing.T) {
+	wav, _ := test.WAV()
+	original, err := io.ReadAll(wav)
+	assert.NoError(t, err)
+	src := &countingReaderAt{r: bytes.NewReader(original)}
+
+	d, err := document.Open(src)
+	assert.NoError(t, err)
+
+	// Add metadata, leaving the samples untouched.
+	assert.NoError(t, d.Insert(2, document.NewList(internal.Must4Byte([]byte("INFO")),
+		document.NewChunk(fourCC("INAM"), []byte("Song\x00\x00")))))
+	assert.NoError(t, d.Chunks[1].SetData([]byte("smpl data")))
+
+	var out bytes.Buffer
+	assert.NoError(t, d.Encode(&out))
+
+	r, err := reader.New(&out)
+	assert.NoError(t, err)
+	chunks, err := r.ReadToEnd()
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 4)
+	assert.Equal(t, []byte("smpl data"), chunks[1].Data)
+	assert.Equal(t, goriffa.FourCCList, chunks[2].Identifier)
+	assert.Equal(t, original[112:], internal.Pad(chunks[3].Data))
+	assert.Nil(t, d.Chunks[3].Data)
+
+	// Encoding again reads the untouched data again.
+	read := src.n
+	assert.NoError(t, d.Encode(io.Discard))
+	assert.Equal(t, read+16+243696, src.n)
+}
+
+func TestOpenLarge(t *testing.T) {
+	test.Long(t)
+
+	// A Wavefile holding 64 MiB of samples, none of which
+	// are held in memory.
+	const size = 64 * 1024 * 1024
+	src := largeWAV{size: size}
+
+	d, err := document.Open(src)
+	assert.NoError(t, err)
+	assert.NoError(t, d.Insert(1, document.NewList(internal.Must4Byte([]byte("INFO")),
+		document.NewChunk(fourCC("INAM"), []byte("Song\x00\x00")))))
+
+	out := filepath.Join(t.TempDir(), "large.wav")
+	f, err := os.Create(out)
+	assert.NoError(t, err)
+	defer f.Close()
+	assert.NoError(t, d.Encode(f))
+
+	info, err := f.Stat()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12+24+8+size+26), info.Size())
+}
+
+func TestOpenLargeRF64(t *testing.T) {
+	test.Long(t)
+
+	const size = 64 * 1024 * 1024
+	d, err := document.Open(largeWAV{size: size})
+	assert.NoError(t, err)
+	d.Identifier = goriffa.FourCCRF64
+
+	// Data smaller than 4 GB is streamed to a plain writer
+	// rather than spooled.
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	var out countingWriter
+	assert.NoError(t, d.Encode(&out))
+	runtime.ReadMemStats(&after)
+
+	assert.Equal(t, int64(12+36+24+8+size), out.n)
+	assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(size/4))
+}
+
+func TestOpenLargerRF64(t *testing.T) {
+	const size = 5 * 1024 * 1024 * 1024
+	d, err := document.Open(largeWAV{size: size})
+	assert.NoError(t, err)
+	assert.Equal(t, goriffa.FourCCRF64, d.Identifier)
+
+	// The RF64 header is written up front, so encoding can
+	// be stopped once it's written.
+	out := &limitedWriter{limit: 128}
+	assert.ErrorIs(t, d.Encode(out), errLimit)
+
+	header := out.buf.Bytes()
+	assert.Equal(t, []byte("RF64\xFF\xFF\xFF\xFFWAVEds64"), header[:16])
+	assert.Equal(t, uint32(internal.LengthDS64), binary.LittleEndian.Uint32(header[16:]))
+	assert.Equal(t, uint64(4+36+24+8+size), binary.LittleEndian.Uint64(header[20:]))
+	assert.Equal(t, uint64(size), binary.LittleEndian.Uint64(header[28:]))
+	assert.Equal(t, []byte("data\xFF\xFF\xFF\xFF"), header[12+36+24:12+36+24+8])
+}
+
+func TestOpenCorrupted(t *testing.T) {
+	data := nestedRIFF(binary.LittleEndian)
+
+	_, err := document.Open(bytes.NewReader(data[:len(data)-4]))
+	assert.ErrorIs(t, err, goriffa.ErrCorrupted)
+
+	// The data changed since it was indexed.
+	src := bytes.NewReader(data)
+	d, err := document.Open(src)
+	assert.NoError(t, err)
+	src.Reset(data[:20])
+
+	_, err = d.Chunks[0].ReadData()
+	assert.ErrorIs(t, err, goriffa.ErrCorrupted)
+	assert.ErrorIs(t, d.Encode(io.Discard), goriffa.ErrCorrupted)
+}
+
+type countingReaderAt struct {
+	r io.ReaderAt
+	n int64
+}
+
+func (c *countingReaderAt) ReadAt(b []byte, offset int64) (int, error) {
+	n, err := c.r.ReadAt(b, offset)
+	c.n += int64(n)
+
+	return n, err
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	c.n += int64(len(b))
+
+	return len(b), nil
+}
+
+var errLimit = errors.New("limit reached")
+
+// limitedWriter buffers the data written to it up to its
+// limit, failing once it's reached.
+type limitedWriter struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (l *limitedWriter) Write(b []byte) (int, error) {
+	if l.buf.Len()+len(b) > l.limit {
+		return 0, errLimit
+	}
+
+	return l.buf.Write(b)
+}
+
+// largeWAV reads as a Wavefile whose "data" chunk holds
+// size bytes of silence, as RF64 data if it would be
+// larger than 4 GB.
+type largeWAV struct {
+	size int64
+}
+
+func (w largeWAV) ReadAt(b []byte, offset int64) (int, error) {
+	format := []byte{1, 0, 1, 0, 0x40, 0x1F, 0, 0, 0x40, 0x1F, 0, 0, 1, 0, 8, 0}
+
+	var header bytes.Buffer
+	if w.size > math.MaxUint32-4-24-8 {
+		ds64 := internal.DS64{RIFFSize: uint64(4 + 36 + 24 + 8 + w.size), DataSize: uint64(w.size)}
+		header.WriteString("RF64")
+		header.Write(internal.LittleEndianUInt32Bytes(math.MaxUint32))
+		header.WriteString("WAVE")
+		header.WriteString("ds64")
+		header.Write(internal.LittleEndianUInt32Bytes(uint32(internal.LengthDS64)))
+		header.Write(ds64.Bytes())
+	} else {
+		header.WriteString("RIFF")
+		header.Write(internal.LittleEndianUInt32Bytes(uint32(4 + 24 + 8 + w.size)))
+		header.WriteString("WAVE")
+	}
+	header.WriteString("fmt ")
+	header.Write(internal.LittleEndianUInt32Bytes(uint32(len(format))))
+	header.Write(format)
+	header.WriteString("data")
+	header.Write(internal.LittleEndianUInt32Bytes(uint32(min64(w.size, math.MaxUint32))))
+
+	total := int64(header.Len()) + w.size
+	if offset >= total {
+		return 0, io.EOF
+	}
+
+	var err error
+	if remaining := total - offset; int64(len(b)) > remaining {
+		b, err = b[:remaining], io.EOF
+	}
+
+	n := 0
+	if offset < int64(header.Len()) {
+		n = copy(b, header.Bytes()[offset:])
+	}
+	silence := b[n:]
+	for i := range silence {
+		silence[i] = 0
+	}
+
+	return len(b), err
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,387 @@
+// Package editor provides a mechanism for editing the
+// chunks of existing RIFF data in place, without reading
+// and rewriting the data in its entirety.
+package editor
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/internal"
+	"github.com/standoffvenus/goriffa/reader"
+)
+
+// File is the RIFF data edited by an Editor, e.g. an
+// *os.File opened for reading and writing.
+//
+// If the File also implements Truncater, the File is
+// truncated whenever an edit shrinks the RIFF data.
+//
+// Any data following the RIFF data (such as further RIFF
+// forms) is kept, and shifted along with the RIFF data's
+// end. If the File implements Stat (as *os.File does),
+// the length of the File is taken from it; otherwise, the
+// File is read to its end to find its length.
+type File interface {
+	io.ReaderAt
+	io.WriterAt
+}
+
+// Truncater is implemented by files that may be
+// truncated, such as *os.File.
+type Truncater interface {
+	Truncate(size int64) error
+}
+
+// stater is implemented by files whose length is known,
+// such as *os.File.
+type stater interface {
+	Stat() (os.FileInfo, error)
+}
+
+// Editor edits the chunks of RIFF data in place. Space
+// taken up by filler chunks (see goriffa.IsFiller) is
+// reused where possible, so data is only shifted when an
+// edited chunk no longer fits. The sizes of the RIFF data
+// and of any enclosing lists are fixed after every edit.
+//
+// Only RIFF and RIFX data may be edited; RF64 and BW64
+// data is not supported.
+//
+// An Editor is NOT concurrent-safe.
+type Editor struct {
+	f   File
+	idx *reader.Index
+}
+
+// shiftBufferSize is the size of the buffer used to
+// shift data when a chunk no longer fits.
+const shiftBufferSize = 64 * 1024
+
+var (
+	errUnsupported error = errors.New("editing RF64 data is not supported")
+	errStaleEntry  error = errors.New("entry not found in the RIFF data")
+)
+
+// New creates a new Editor of the RIFF data held by the
+// provided file, indexing its chunks as reader.NewIndex
+// does.
+func New(f File) (*Editor, error) {
+	e := &Editor{f: f}
+	if err := e.reindex(); err != nil {
+		return nil, err
+	}
+
+	if id := e.idx.Identifier(); id != goriffa.FourCCRIFF && id != goriffa.FourCCRIFX {
+		return nil, fmt.Errorf("%w: %q", errUnsupported, id)
+	}
+
+	return e, nil
+}
+
+// Entries returns every chunk of the RIFF data, as
+// reader.Index.Entries does. Every edit re-indexes the
+// RIFF data, after which entries returned earlier must
+// not be used.
+func (e *Editor) Entries() []reader.Entry {
+	return e.idx.Entries()
+}
+
+// Lookup returns the first chunk with the provided
+// identifier, as reader.Index.Lookup does.
+func (e *Editor) Lookup(identifier internal.FourCC) (reader.Entry, bool) {
+	return e.idx.Lookup(identifier)
+}
+
+// Replace will replace the data of the provided chunk.
+// The chunk is rewritten in place if it fits within its
+// current space, together with any filler chunks that
+// directly follow it; the space left over is turned into
+// a "JUNK" chunk. Otherwise, the data following the chunk
+// is shifted.
+func (e *Editor) Replace(entry reader.Entry, data []byte) error {
+	position, err := e.find(entry)
+	if err != nil {
+		return err
+	}
+
+	span := e.fillerAfter(position, entry.Offset+entry.ByteLength())
+
+	return e.put(internal.Chunk{Identifier: entry.Identifier, Data: data},
+		entry.Offset,
+		entry.ByteLength(),
+		span-entry.Offset,
+		entry.Parent)
+}
+
+// Remove will remove the provided chunk by turning it
+// into a "JUNK" chunk of the same size, so no data needs
+// to be shifted. Note the chunk's data is left in place
+// as the "JUNK" chunk's data.
+func (e *Editor) Remove(entry reader.Entry) error {
+	if _, err := e.find(entry); err != nil {
+		return err
+	}
+
+	if _, err := internal.WriteAt(e.f, goriffa.FourCCJunk[:], entry.Offset); err != nil {
+		return err
+	}
+
+	return e.reindex()
+}
+
+// Append will append the provided chunk to the list at
+// position parent within Entries - or, if parent is -1,
+// to the RIFF data itself. If the list (or RIFF data)
+// ends with filler chunks large enough to hold the chunk,
+// it is written in their place. Otherwise, the data
+// following the list is shifted.
+func (e *Editor) Append(parent int, c internal.Chunk) error {
+	entries := e.idx.Entries()
+
+	end := int64(internal.LengthChunkHeader) + int64(e.idx.Size64())
+	if parent != -1 {
+		if parent < 0 || parent >= len(entries) || !goriffa.IsList(entries[parent].Identifier) {
+			return fmt.Errorf("%w: no list at position %d", errStaleEntry, parent)
+		}
+		end = entries[parent].Offset + entries[parent].ByteLength()
+	}
+
+	// Find the filler chunks at the end of the list.
+	offset := end
+	for i := len(entries) - 1; i > parent; i-- {
+		if entries[i].Parent != parent {
+			continue
+		}
+		if !goriffa.IsFiller(entries[i].Identifier) {
+			break
+		}
+		offset = entries[i].Offset
+	}
+
+	// A list's size excludes its padding byte.
+	if parent != -1 && offset == end {
+		offset = entries[parent].Offset + int64(internal.LengthChunkHeader) + entries[parent].Size
+	}
+
+	return e.put(c, offset, 0, end-offset, parent)
+}
+
+// put will write the provided chunk at offset, replacing
+// the fixed bytes at offset. If the chunk fits within the
+// span of bytes at offset, the rest of the span is turned
+// into a "JUNK" chunk; otherwise, the data following the
+// fixed bytes is shifted and the sizes of the enclosing
+// lists are fixed.
+func (e *Editor) put(c internal.Chunk, offset, fixed, span int64, parent int) error {
+	length := c.ByteLength()
+	if int64(len(c.Data)) > math.MaxUint32 {
+		return fmt.Errorf("%w: chunk too large - size overflow", internal.ErrCorrupted)
+	}
+
+	switch rest := span - length; {
+	case rest == 0:
+		if err := e.writeChunk(c, offset); err != nil {
+			return err
+		}
+
+		return e.reindex()
+	case rest >= int64(internal.LengthChunkHeader):
+		if err := e.writeChunk(c, offset); err != nil {
+			return err
+		}
+		if err := e.writeHeader(goriffa.FourCCJunk, rest-int64(internal.LengthChunkHeader), offset+length); err != nil {
+			return err
+		}
+
+		return e.reindex()
+	}
+
+	delta := length - fixed
+	if err := e.checkSizes(parent, delta); err != nil {
+		return err
+	}
+	if err := e.shift(offset+fixed, delta); err != nil {
+		return err
+	}
+	if err := e.writeChunk(c, offset); err != nil {
+		return err
+	}
+
+	return e.fixSizes(parent, delta)
+}
+
+// shift will move the data from offset up to the end of
+// the file by delta bytes.
+func (e *Editor) shift(offset, delta int64) error {
+	end, err := e.length()
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, shiftBufferSize)
+
+	// Move the data back to front when growing, so
+	// nothing is overwritten before it has been moved.
+	for moved := int64(0); moved < end-offset; {
+		n := int64(len(buf))
+		if remaining := end - offset - moved; remaining < n {
+			n = remaining
+		}
+
+		from := offset + moved
+		if delta > 0 {
+			from = end - moved - n
+		}
+
+		if read, err := e.f.ReadAt(buf[:n], from); int64(read) < n {
+			return err
+		}
+		if _, err := internal.WriteAt(e.f, buf[:n], from+delta); err != nil {
+			return err
+		}
+		moved += n
+	}
+
+	if t, ok := e.f.(Truncater); ok && delta < 0 {
+		return t.Truncate(end + delta)
+	}
+
+	return nil
+}
+
+// length returns the length of the file, which is never
+// less than the end of the RIFF data.
+func (e *Editor) length() (int64, error) {
+	end := int64(internal.LengthChunkHeader) + int64(e.idx.Size64())
+	if s, ok := e.f.(stater); ok {
+		info, err := s.Stat()
+		if err != nil {
+			return 0, err
+		}
+		if info.Size() > end {
+			end = info.Size()
+		}
+
+		return end, nil
+	}
+
+	buf := make([]byte, shiftBufferSize)
+	for {
+		n, err := e.f.ReadAt(buf, end)
+		end += int64(n)
+		if errors.Is(err, io.EOF) {
+			return end, nil
+		} else if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// checkSizes will return an error if growing the list at
+// position parent - and all of its enclosing lists - by
+// delta bytes would overflow their size fields.
+func (e *Editor) checkSizes(parent int, delta int64) error {
+	entries := e.idx.Entries()
+	for ; parent != -1; parent = entries[parent].Parent {
+		if entries[parent].Size+delta > math.MaxUint32 {
+			return fmt.Errorf("%w: list %d too large - size overflow", internal.ErrCorrupted, parent)
+		}
+	}
+	if int64(e.idx.Size64())+delta > math.MaxUint32 {
+		return fmt.Errorf("%w: RIFF data too large - size overflow", internal.ErrCorrupted)
+	}
+
+	return nil
+}
+
+// fixSizes will grow the size of the list at position
+// parent - and of all its enclosing lists, as well as the
+// RIFF data - by delta bytes, after which the RIFF data
+// is re-indexed.
+func (e *Editor) fixSizes(parent int, delta int64) error {
+	entries := e.idx.Entries()
+	for ; parent != -1; parent = entries[parent].Parent {
+		list := entries[parent]
+		if err := e.writeSize(list.Size+delta, list.Offset+int64(len(list.Identifier))); err != nil {
+			return err
+		}
+	}
+
+	if err := e.writeSize(int64(e.idx.Size64())+delta, int64(len(goriffa.FourCCRIFF))); err != nil {
+		return err
+	}
+
+	return e.reindex()
+}
+
+// fillerAfter returns the offset at which the filler
+// chunks directly following the chunk at position
+// (which ends at offset end) end themselves.
+func (e *Editor) fillerAfter(position int, end int64) int64 {
+	entries := e.idx.Entries()
+	for i := position + 1; i < len(entries); i++ {
+		if entries[i].Parent == entries[position].Parent && entries[i].Offset == end {
+			if !goriffa.IsFiller(entries[i].Identifier) {
+				break
+			}
+			end += entries[i].ByteLength()
+		}
+	}
+
+	return end
+}
+
+// find returns the position of the provided entry
+// within Entries.
+func (e *Editor) find(entry reader.Entry) (int, error) {
+	for i, candidate := range e.idx.Entries() {
+		if candidate == entry {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %q at offset %d", errStaleEntry, entry.Identifier, entry.Offset)
+}
+
+func (e *Editor) writeChunk(c internal.Chunk, offset int64) error {
+	if err := e.writeHeader(c.Identifier, int64(len(c.Data)), offset); err != nil {
+		return err
+	}
+
+	_, err := internal.WriteAt(e.f, internal.Pad(c.Data), offset+int64(internal.LengthChunkHeader))
+
+	return err
+}
+
+func (e *Editor) writeHeader(identifier internal.FourCC, size, offset int64) error {
+	if _, err := internal.WriteAt(e.f, identifier[:], offset); err != nil {
+		return err
+	}
+
+	return e.writeSize(size, offset+int64(len(identifier)))
+}
+
+func (e *Editor) writeSize(size, offset int64) error {
+	_, err := internal.WriteAt(e.f, internal.UInt32Bytes(e.byteOrder(), uint32(size)), offset)
+
+	return err
+}
+
+func (e *Editor) byteOrder() binary.ByteOrder {
+	return e.idx.ByteOrder()
+}
+
+func (e *Editor) reindex() error {
+	idx, err := reader.NewIndex(e.f)
+	if err != nil {
+		return err
+	}
+	e.idx = idx
+
+	return nil
+}
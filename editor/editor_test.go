@@ -0,0 +1,298 @@
+package editor_test
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/editor"
+	"github.com/standoffvenus/goriffa/internal"
+	"github.com/standoffvenus/goriffa/internal/test"
+	"github.com/standoffvenus/goriffa/writer"
+	"github.com/stretchr/testify/assert"
+)
+
+func Example() {
+	var buf test.Buffer
+	w, err := writer.New(&buf, test.FileType)
+	if err != nil {
+		panic(err)
+	}
+	info, err := w.CreateList(internal.StringMust4Byte("INFO"))
+	if err != nil {
+		panic(err)
+	}
+	if _, err := info.WriteChunk(goriffa.Chunk{Identifier: test.FourCC("INAM"), Data: []byte("Old name")}); err != nil {
+		panic(err)
+	}
+	if _, err := w.WriteChunk(goriffa.Chunk{Identifier: goriffa.FourCCJunk, Data: make([]byte, 16)}); err != nil {
+		panic(err)
+	}
+	if err := w.Close(); err != nil {
+		panic(err)
+	}
+
+	f, err := os.CreateTemp(os.TempDir(), "")
+	if err != nil {
+		panic(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		panic(err)
+	}
+
+	e, err := editor.New(f)
+	if err != nil {
+		panic(err)
+	}
+
+	name, _ := e.Lookup(test.FourCC("INAM"))
+	if err := e.Replace(name, []byte("A much longer name")); err != nil {
+		panic(err)
+	}
+
+	for _, entry := range e.Entries() {
+		fmt.Printf("%q at offset %d (%d bytes)\n", entry.Identifier, entry.Offset, entry.Size)
+	}
+
+	// Output:
+	// "LIST" at offset 12 (30 bytes)
+	// "INAM" at offset 24 (18 bytes)
+	// "JUNK" at offset 50 (16 bytes)
+}
+
+func TestReplaceSameLength(t *testing.T) {
+	f := newFile(t,
+		test.Chunk("abcd", "1234"),
+		test.Chunk("efgh", "5678"))
+
+	e, err := editor.New(f)
+	assert.NoError(t, err)
+
+	entry, _ := e.Lookup(test.FourCC("abcd"))
+	assert.NoError(t, e.Replace(entry, []byte("4321")))
+
+	assert.Equal(t, test.RIFF(test.Chunk("abcd", "4321"), test.Chunk("efgh", "5678")), contents(t, f))
+}
+
+func TestReplaceSmallerLeavesJunk(t *testing.T) {
+	f := newFile(t,
+		test.Chunk("abcd", "0123456789abcdef"),
+		test.Chunk("efgh", "5678"))
+
+	e, err := editor.New(f)
+	assert.NoError(t, err)
+
+	entry, _ := e.Lookup(test.FourCC("abcd"))
+	assert.NoError(t, e.Replace(entry, []byte("42")))
+
+	assert.Equal(t, test.RIFF(
+		test.Chunk("abcd", "42"),
+		test.Header("JUNK", 6), []byte("abcdef"),
+		test.Chunk("efgh", "5678")), contents(t, f))
+}
+
+func TestReplaceSlightlySmallerShifts(t *testing.T) {
+	f := newFile(t,
+		test.Chunk("abcd", "1234"),
+		test.Chunk("efgh", "5678"))
+
+	e, err := editor.New(f)
+	assert.NoError(t, err)
+
+	entry, _ := e.Lookup(test.FourCC("abcd"))
+	assert.NoError(t, e.Replace(entry, []byte("12")))
+
+	// There is no room for a filler chunk, so the
+	// following data is shifted and the file truncated.
+	assert.Equal(t, test.RIFF(test.Chunk("abcd", "12"), test.Chunk("efgh", "5678")), contents(t, f))
+}
+
+func TestReplaceLargerShifts(t *testing.T) {
+	f := newFile(t,
+		test.Chunk("abcd", "1"),
+		test.List("INFO",
+			test.Chunk("INAM", "odd"),
+			test.Chunk("ICMT", "x")),
+		test.Chunk("efgh", "5678"))
+
+	e, err := editor.New(f)
+	assert.NoError(t, err)
+
+	entry, _ := e.Lookup(test.FourCC("INAM"))
+	assert.NoError(t, e.Replace(entry, []byte("a longer name")))
+
+	assert.Equal(t, test.RIFF(
+		test.Chunk("abcd", "1"),
+		test.List("INFO",
+			test.Chunk("INAM", "a longer name"),
+			test.Chunk("ICMT", "x")),
+		test.Chunk("efgh", "5678")), contents(t, f))
+}
+
+func TestReplaceLargerReusesJunk(t *testing.T) {
+	f := newFile(t,
+		test.Chunk("abcd", "1"),
+		test.Chunk("JUNK", "0123"),
+		test.Chunk("PAD ", "01234567"),
+		test.Chunk("efgh", "5678"))
+
+	e, err := editor.New(f)
+	assert.NoError(t, err)
+
+	entry, _ := e.Lookup(test.FourCC("abcd"))
+	assert.NoError(t, e.Replace(entry, []byte("0123456789")))
+
+	assert.Equal(t, test.RIFF(
+		test.Chunk("abcd", "0123456789"),
+		test.Header("JUNK", 12), test.Header("PAD ", 8)[4:], []byte("01234567"),
+		test.Chunk("efgh", "5678")), contents(t, f))
+}
+
+func TestRemove(t *testing.T) {
+	f := newFile(t,
+		test.Chunk("abcd", "1234"),
+		test.Chunk("efgh", "5678"))
+
+	e, err := editor.New(f)
+	assert.NoError(t, err)
+
+	entry, _ := e.Lookup(test.FourCC("abcd"))
+	assert.NoError(t, e.Remove(entry))
+
+	assert.Equal(t, test.RIFF(test.Chunk("JUNK", "1234"), test.Chunk("efgh", "5678")), contents(t, f))
+
+	// Entries are re-indexed after the edit.
+	assert.Error(t, e.Remove(entry))
+}
+
+func TestAppend(t *testing.T) {
+	f := newFile(t,
+		test.List("INFO", test.Chunk("INAM", "odd")),
+		test.Chunk("efgh", "5678"))
+
+	e, err := editor.New(f)
+	assert.NoError(t, err)
+
+	assert.NoError(t, e.Append(-1, goriffa.Chunk{Identifier: test.FourCC("ijkl"), Data: []byte("x")}))
+	assert.NoError(t, e.Append(0, goriffa.Chunk{Identifier: test.FourCC("ICMT"), Data: []byte("hi")}))
+
+	assert.Equal(t, test.RIFF(
+		test.List("INFO",
+			test.Chunk("INAM", "odd"),
+			test.Chunk("ICMT", "hi")),
+		test.Chunk("efgh", "5678"),
+		test.Chunk("ijkl", "x")), contents(t, f))
+}
+
+func TestAppendReusesTrailingJunk(t *testing.T) {
+	f := newFile(t,
+		test.List("INFO",
+			test.Chunk("INAM", "odd"),
+			test.Chunk("JUNK", "0123456789")),
+		test.Chunk("efgh", "5678"))
+
+	e, err := editor.New(f)
+	assert.NoError(t, err)
+	assert.NoError(t, e.Append(0, goriffa.Chunk{Identifier: test.FourCC("ICMT"), Data: []byte("hi")}))
+
+	assert.Equal(t, test.RIFF(
+		test.List("INFO",
+			test.Chunk("INAM", "odd"),
+			test.Chunk("ICMT", "hi"),
+			test.Header("JUNK", 0)),
+		test.Chunk("efgh", "5678")), contents(t, f))
+}
+
+func TestAppendNotAList(t *testing.T) {
+	f := newFile(t, test.Chunk("abcd", "1234"))
+
+	e, err := editor.New(f)
+	assert.NoError(t, err)
+	assert.Error(t, e.Append(0, goriffa.Chunk{Identifier: test.FourCC("efgh")}))
+	assert.Error(t, e.Append(1, goriffa.Chunk{Identifier: test.FourCC("efgh")}))
+}
+
+func TestTrailingData(t *testing.T) {
+	trailing := []byte("TRAILING")
+
+	for name, open := range map[string]func(*os.File) editor.File{
+		"file":      func(f *os.File) editor.File { return f },
+		"no length": func(f *os.File) editor.File { return plainFile{f, f, f} },
+	} {
+		t.Run(name, func(t *testing.T) {
+			f := writeFile(t, append(test.RIFF(
+				test.Chunk("abcd", "1234"),
+				test.Chunk("efgh", "5678")), trailing...))
+
+			e, err := editor.New(open(f))
+			assert.NoError(t, err)
+
+			entry, _ := e.Lookup(test.FourCC("abcd"))
+			assert.NoError(t, e.Replace(entry, []byte("a longer chunk")))
+			assert.NoError(t, e.Append(-1, goriffa.Chunk{Identifier: test.FourCC("ijkl"), Data: []byte("x")}))
+			assert.Equal(t, append(test.RIFF(
+				test.Chunk("abcd", "a longer chunk"),
+				test.Chunk("efgh", "5678"),
+				test.Chunk("ijkl", "x")), trailing...), contents(t, f))
+
+			entry, _ = e.Lookup(test.FourCC("efgh"))
+			assert.NoError(t, e.Replace(entry, []byte("56")))
+			assert.Equal(t, append(test.RIFF(
+				test.Chunk("abcd", "a longer chunk"),
+				test.Chunk("efgh", "56"),
+				test.Chunk("ijkl", "x")), trailing...), contents(t, f))
+		})
+	}
+}
+
+func TestNewRF64(t *testing.T) {
+	var buf test.Buffer
+	w, err := writer.New(&buf, test.FileType, writer.PromoteToRF64())
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	// Pretend the data grew beyond 4 GB.
+	b := buf.Bytes()
+	copy(b, goriffa.FourCCRF64[:])
+	copy(b[4:], internal.LittleEndianUInt32Bytes(math.MaxUint32))
+	copy(b[12:], goriffa.FourCCDS64[:])
+
+	_, err = editor.New(writeFile(t, b))
+	assert.Error(t, err)
+}
+
+func newFile(t *testing.T, chunks ...[]byte) *os.File {
+	return writeFile(t, test.RIFF(chunks...))
+}
+
+func writeFile(t *testing.T, b []byte) *os.File {
+	f, err := os.Create(filepath.Join(t.TempDir(), "riff"))
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = f.Close() })
+
+	_, writeErr := f.Write(b)
+	assert.NoError(t, writeErr)
+
+	return f
+}
+
+func contents(t *testing.T, f *os.File) []byte {
+	b, err := io.ReadAll(io.NewSectionReader(f, 0, 1<<20))
+	assert.NoError(t, err)
+
+	return b
+}
+
+// plainFile hides all but the methods of an *os.File
+// an editor.File may implement.
+type plainFile struct {
+	io.ReaderAt
+	io.WriterAt
+	editor.Truncater
+}
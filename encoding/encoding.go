@@ -0,0 +1,582 @@
+// Package encoding maps Go structs to the data of RIFF
+// chunks and back, so a chunk holding a fixed binary
+// layout is supported by declaring a struct:
+//
+//  type sampler struct {
+//    Manufacturer uint32
+//    Product      uint32
+//    Name         string `riff:"zstring"`
+//    Loops        []loop `riff:"prefix=uint32"`
+//    Extra        []byte `riff:"rest"`
+//  }
+//
+// Fields are encoded in order, without any alignment.
+// Unexported fields, and fields tagged `riff:"-"`, are
+// skipped. Fields are encoded according to their type:
+//
+// - Integers of a fixed size (e.g. uint16 or int32, and
+// types based on them) and floating-point numbers are
+// encoded in the byte order in use: little-endian unless
+// MarshalOrder or UnmarshalOrder is used (e.g. for RIFX
+// data).
+//
+// - Byte arrays, such as FOURCCs (goriffa.FileType), are
+// encoded as they are. Other arrays are encoded element
+// by element.
+//
+// - Structs are encoded field by field.
+//
+// - Strings and slices require a tag describing their
+// length, see below.
+//
+// The tag of a string or []byte field is one of:
+//
+// - "size=N": the field takes up exactly N bytes. Shorter
+// values are padded with NUL bytes, which are trimmed
+// from strings when decoding.
+//
+// - "zstring": the field is terminated by a NUL byte.
+//
+// - "prefix=T": the field is preceded by its length, an
+// integer of type T - uint8, uint16 or uint32.
+//
+// - "rest": the field takes up the rest of the data, so
+// it must be the last field.
+//
+// Slices of any other type support "prefix=T" - where
+// the length is the number of elements - and "rest".
+// Decoding fails for elements taking up no data. Tags
+// a field's type doesn't support make both encoding and
+// decoding fail with ErrUnsupported.
+package encoding
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/standoffvenus/goriffa/internal"
+)
+
+// ErrUnsupported is returned when a value cannot be
+// encoded or decoded because of its type or its fields'
+// tags.
+var ErrUnsupported error = errors.New("unsupported value")
+
+// tagName is the key of the struct tags read by Marshal
+// and Unmarshal.
+const tagName = "riff"
+
+// field describes how a field is encoded, as given by
+// its tag.
+type field struct {
+	name string
+
+	size    int
+	zstring bool
+	prefix  reflect.Kind
+	rest    bool
+}
+
+// Marshal will encode the provided struct (or pointer to
+// a struct) as little-endian chunk data.
+func Marshal(v interface{}) ([]byte, error) {
+	return MarshalOrder(v, binary.LittleEndian)
+}
+
+// MarshalOrder works like Marshal, except that numbers
+// are encoded in the provided byte order.
+func MarshalOrder(v interface{}, order binary.ByteOrder) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %T is not a struct", ErrUnsupported, v)
+	}
+
+	e := &encoder{order: order}
+	if err := e.encode(rv, field{name: rv.Type().Name()}); err != nil {
+		return nil, err
+	}
+
+	return e.data, nil
+}
+
+// Unmarshal will decode the provided little-endian chunk
+// data into the struct v points to. Any data left over
+// once every field is decoded is ignored.
+//
+// If the data is too short (or otherwise doesn't match
+// the struct), an error wrapping goriffa.ErrBadChunk is
+// returned.
+func Unmarshal(data []byte, v interface{}) error {
+	return UnmarshalOrder(data, v, binary.LittleEndian)
+}
+
+// UnmarshalOrder works like Unmarshal, except that
+// numbers are decoded in the provided byte order.
+func UnmarshalOrder(data []byte, v interface{}, order binary.ByteOrder) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: %T is not a pointer to a struct", ErrUnsupported, v)
+	}
+
+	d := &decoder{data: data, order: order}
+
+	return d.decode(rv.Elem(), field{name: rv.Elem().Type().Name()})
+}
+
+type encoder struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+func (e *encoder) encode(v reflect.Value, f field) error {
+	switch v.Kind() {
+	case reflect.Uint8, reflect.Int8:
+		e.data = append(e.data, byte(integer(v)))
+	case reflect.Uint16, reflect.Int16:
+		e.data = append(e.data, make([]byte, 2)...)
+		e.order.PutUint16(e.data[len(e.data)-2:], uint16(integer(v)))
+	case reflect.Uint32, reflect.Int32, reflect.Float32:
+		e.data = append(e.data, make([]byte, 4)...)
+		e.order.PutUint32(e.data[len(e.data)-4:], uint32(integer(v)))
+	case reflect.Uint64, reflect.Int64, reflect.Float64:
+		e.data = append(e.data, make([]byte, 8)...)
+		e.order.PutUint64(e.data[len(e.data)-8:], integer(v))
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			for i := 0; i < v.Len(); i++ {
+				e.data = append(e.data, byte(v.Index(i).Uint()))
+			}
+
+			return nil
+		}
+
+		return e.encodeElements(v, f)
+	case reflect.Struct:
+		return forFields(v, func(fv reflect.Value, ff field) error {
+			return e.encode(fv, ff)
+		})
+	case reflect.String:
+		return e.encodeBytes([]byte(v.String()), f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return e.encodeBytes(v.Bytes(), f)
+		}
+
+		switch {
+		case f.prefix != reflect.Invalid:
+			if err := e.encodePrefix(v.Len(), f); err != nil {
+				return err
+			}
+		case !f.rest:
+			return fmt.Errorf("%w: field %s requires a prefix or rest tag", ErrUnsupported, f.name)
+		}
+
+		return e.encodeElements(v, f)
+	default:
+		return fmt.Errorf("%w: field %s of type %s", ErrUnsupported, f.name, v.Type())
+	}
+
+	return nil
+}
+
+func (e *encoder) encodeElements(v reflect.Value, f field) error {
+	for i := 0; i < v.Len(); i++ {
+		if err := e.encode(v.Index(i), field{name: f.name + "[" + strconv.Itoa(i) + "]"}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *encoder) encodeBytes(b []byte, f field) error {
+	switch {
+	case f.size > 0:
+		if len(b) > f.size {
+			return fmt.Errorf("%w: field %s is %d bytes long, more than its size (%d)", ErrUnsupported, f.name, len(b), f.size)
+		}
+		e.data = append(e.data, b...)
+		e.data = append(e.data, make([]byte, f.size-len(b))...)
+	case f.zstring:
+		for _, c := range b {
+			if c == 0 {
+				return fmt.Errorf("%w: field %s holds a NUL byte", ErrUnsupported, f.name)
+			}
+		}
+		e.data = append(e.data, b...)
+		e.data = append(e.data, 0)
+	case f.prefix != reflect.Invalid:
+		if err := e.encodePrefix(len(b), f); err != nil {
+			return err
+		}
+		e.data = append(e.data, b...)
+	case f.rest:
+		e.data = append(e.data, b...)
+	default:
+		return fmt.Errorf("%w: field %s requires a size, zstring, prefix or rest tag", ErrUnsupported, f.name)
+	}
+
+	return nil
+}
+
+func (e *encoder) encodePrefix(n int, f field) error {
+	if uint64(n) > maxPrefix(f.prefix) {
+		return fmt.Errorf("%w: field %s is too long (%d) for its prefix", ErrUnsupported, f.name, n)
+	}
+
+	return e.encode(reflect.ValueOf(uint64(n)).Convert(prefixType(f.prefix)), field{name: f.name})
+}
+
+type decoder struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+func (d *decoder) decode(v reflect.Value, f field) error {
+	switch v.Kind() {
+	case reflect.Uint8, reflect.Int8:
+		b, err := d.take(1, f)
+		if err != nil {
+			return err
+		}
+		setInteger(v, uint64(b[0]))
+	case reflect.Uint16, reflect.Int16:
+		b, err := d.take(2, f)
+		if err != nil {
+			return err
+		}
+		setInteger(v, uint64(d.order.Uint16(b)))
+	case reflect.Uint32, reflect.Int32, reflect.Float32:
+		b, err := d.take(4, f)
+		if err != nil {
+			return err
+		}
+		setInteger(v, uint64(d.order.Uint32(b)))
+	case reflect.Uint64, reflect.Int64, reflect.Float64:
+		b, err := d.take(8, f)
+		if err != nil {
+			return err
+		}
+		setInteger(v, d.order.Uint64(b))
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := d.take(v.Len(), f)
+			if err != nil {
+				return err
+			}
+			for i, c := range b {
+				v.Index(i).SetUint(uint64(c))
+			}
+
+			return nil
+		}
+
+		for i := 0; i < v.Len(); i++ {
+			if err := d.decode(v.Index(i), field{name: f.name + "[" + strconv.Itoa(i) + "]"}); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		return forFields(v, func(fv reflect.Value, ff field) error {
+			return d.decode(fv, ff)
+		})
+	case reflect.String:
+		b, err := d.decodeBytes(f)
+		if err != nil {
+			return err
+		}
+		if f.size > 0 {
+			b = trimNUL(b)
+		}
+		v.SetString(string(b))
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := d.decodeBytes(f)
+			if err != nil {
+				return err
+			}
+			v.SetBytes(append([]byte(nil), b...))
+
+			return nil
+		}
+
+		return d.decodeElements(v, f)
+	default:
+		return fmt.Errorf("%w: field %s of type %s", ErrUnsupported, f.name, v.Type())
+	}
+
+	return nil
+}
+
+func (d *decoder) decodeElements(v reflect.Value, f field) error {
+	n := -1
+	switch {
+	case f.prefix != reflect.Invalid:
+		length, err := d.decodePrefix(f)
+		if err != nil {
+			return err
+		}
+		n = int(length)
+	case !f.rest:
+		return fmt.Errorf("%w: field %s requires a prefix or rest tag", ErrUnsupported, f.name)
+	}
+
+	elements := reflect.MakeSlice(v.Type(), 0, 0)
+	for i := 0; n < 0 && len(d.data) > 0 || i < n; i++ {
+		left := len(d.data)
+		element := reflect.New(v.Type().Elem()).Elem()
+		if err := d.decode(element, field{name: f.name + "[" + strconv.Itoa(i) + "]"}); err != nil {
+			return err
+		}
+		if len(d.data) == left {
+			// Otherwise, there would be no end to the elements.
+			return fmt.Errorf("%w: elements of field %s take up no data", ErrUnsupported, f.name)
+		}
+		elements = reflect.Append(elements, element)
+	}
+	v.Set(elements)
+
+	return nil
+}
+
+func (d *decoder) decodeBytes(f field) ([]byte, error) {
+	switch {
+	case f.size > 0:
+		return d.take(f.size, f)
+	case f.zstring:
+		for i, c := range d.data {
+			if c == 0 {
+				b := d.data[:i]
+				d.data = d.data[i+1:]
+
+				return b, nil
+			}
+		}
+
+		return nil, fmt.Errorf("%w: field %s is not NUL-terminated", internal.ErrBadChunk, f.name)
+	case f.prefix != reflect.Invalid:
+		n, err := d.decodePrefix(f)
+		if err != nil {
+			return nil, err
+		}
+
+		return d.take(int(n), f)
+	case f.rest:
+		return d.take(len(d.data), f)
+	default:
+		return nil, fmt.Errorf("%w: field %s requires a size, zstring, prefix or rest tag", ErrUnsupported, f.name)
+	}
+}
+
+func (d *decoder) decodePrefix(f field) (uint64, error) {
+	v := reflect.New(prefixType(f.prefix)).Elem()
+	if err := d.decode(v, field{name: f.name}); err != nil {
+		return 0, err
+	}
+
+	n := v.Uint()
+	if n > uint64(len(d.data)) {
+		// Elements must take up at least one byte (see
+		// decodeElements), so the data cannot hold more.
+		return 0, fmt.Errorf("%w: field %s is longer (%d) than the data left", internal.ErrBadChunk, f.name, n)
+	}
+
+	return n, nil
+}
+
+// take returns the next n bytes of the data.
+func (d *decoder) take(n int, f field) ([]byte, error) {
+	if n > len(d.data) {
+		return nil, fmt.Errorf("%w: field %s needs %d bytes, %d left", internal.ErrBadChunk, f.name, n, len(d.data))
+	}
+
+	b := d.data[:n]
+	d.data = d.data[n:]
+
+	return b, nil
+}
+
+// forFields calls fn for every exported field of the
+// struct not tagged "-", along with how it's encoded.
+// The tags of every field are checked before fn is
+// called for any of them.
+func forFields(v reflect.Value, fn func(reflect.Value, field) error) error {
+	var (
+		t       = v.Type()
+		fields  []field
+		indices []int
+	)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		tag := sf.Tag.Get(tagName)
+		if tag == "-" {
+			continue
+		}
+
+		f, err := parseTag(t.Name()+"."+sf.Name, tag)
+		if err != nil {
+			return err
+		}
+		if err := f.check(sf.Type); err != nil {
+			return err
+		}
+		if n := len(fields); n > 0 && fields[n-1].rest {
+			return fmt.Errorf("%w: field %s follows field %s, which takes up the rest of the data", ErrUnsupported, f.name, fields[n-1].name)
+		}
+
+		fields = append(fields, f)
+		indices = append(indices, i)
+	}
+
+	for i, f := range fields {
+		if err := fn(v.Field(indices[i]), f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func parseTag(name, tag string) (field, error) {
+	f := field{name: name}
+	if tag == "" {
+		return f, nil
+	}
+
+	for _, option := range strings.Split(tag, ",") {
+		key, value := option, ""
+		if i := strings.IndexByte(option, '='); i >= 0 {
+			key, value = option[:i], option[i+1:]
+		}
+
+		switch key {
+		case "size":
+			size, err := strconv.Atoi(value)
+			if err != nil || size <= 0 {
+				return f, fmt.Errorf("%w: field %s has invalid size %q", ErrUnsupported, name, value)
+			}
+			f.size = size
+		case "zstring":
+			f.zstring = true
+		case "prefix":
+			switch value {
+			case "uint8":
+				f.prefix = reflect.Uint8
+			case "uint16":
+				f.prefix = reflect.Uint16
+			case "uint32":
+				f.prefix = reflect.Uint32
+			default:
+				return f, fmt.Errorf("%w: field %s has invalid prefix %q", ErrUnsupported, name, value)
+			}
+		case "rest":
+			f.rest = true
+		default:
+			return f, fmt.Errorf("%w: field %s has unknown tag option %q", ErrUnsupported, name, option)
+		}
+	}
+
+	return f, nil
+}
+
+// check returns an error if the field's tag doesn't suit
+// its type: the tag options describe the length of
+// strings and slices, and "size" and "zstring" only
+// apply to strings and byte slices.
+func (f field) check(t reflect.Type) error {
+	switch {
+	case f.size == 0 && !f.zstring && f.prefix == reflect.Invalid && !f.rest:
+		return nil
+	case t.Kind() == reflect.String, t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return nil
+	case t.Kind() == reflect.Slice:
+		if f.size > 0 || f.zstring {
+			return fmt.Errorf("%w: field %s of type %s supports only prefix and rest tags", ErrUnsupported, f.name, t)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("%w: field %s of type %s supports no size, zstring, prefix or rest tag", ErrUnsupported, f.name, t)
+	}
+}
+
+// integer returns the bits of the provided integer or
+// floating-point number.
+func integer(v reflect.Value) uint64 {
+	switch v.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(v.Int())
+	case reflect.Float32:
+		return uint64(math.Float32bits(float32(v.Float())))
+	case reflect.Float64:
+		return math.Float64bits(v.Float())
+	default:
+		return v.Uint()
+	}
+}
+
+// setInteger sets the provided integer or floating-point
+// number from its bits.
+func setInteger(v reflect.Value, bits uint64) {
+	switch v.Kind() {
+	case reflect.Int8:
+		v.SetInt(int64(int8(bits)))
+	case reflect.Int16:
+		v.SetInt(int64(int16(bits)))
+	case reflect.Int32:
+		v.SetInt(int64(int32(bits)))
+	case reflect.Int64:
+		v.SetInt(int64(bits))
+	case reflect.Float32:
+		v.SetFloat(float64(math.Float32frombits(uint32(bits))))
+	case reflect.Float64:
+		v.SetFloat(math.Float64frombits(bits))
+	default:
+		v.SetUint(bits)
+	}
+}
+
+func prefixType(kind reflect.Kind) reflect.Type {
+	switch kind {
+	case reflect.Uint8:
+		return reflect.TypeOf(uint8(0))
+	case reflect.Uint16:
+		return reflect.TypeOf(uint16(0))
+	default:
+		return reflect.TypeOf(uint32(0))
+	}
+}
+
+func maxPrefix(kind reflect.Kind) uint64 {
+	switch kind {
+	case reflect.Uint8:
+		return math.MaxUint8
+	case reflect.Uint16:
+		return math.MaxUint16
+	default:
+		return math.MaxUint32
+	}
+}
+
+// trimNUL returns b up to its first NUL byte.
+func trimNUL(b []byte) []byte {
+	for i, c := range b {
+		if c == 0 {
+			return b[:i]
+		}
+	}
+
+	return b
+}
@@ -0,0 +1,285 @@
+package encoding_test
+
+import (
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/encoding"
+	"github.com/standoffvenus/goriffa/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+type loop struct {
+	ID    uint32
+	Start uint32
+	End   uint32
+}
+
+type sampler struct {
+	Manufacturer uint32
+	Product      uint32
+	Name         string `riff:"zstring"`
+	Loops        []loop `riff:"prefix=uint32"`
+	Extra        []byte `riff:"rest"`
+}
+
+func Example() {
+	data, err := encoding.Marshal(sampler{
+		Manufacturer: 0x47,
+		Name:         "Kick",
+		Loops:        []loop{{ID: 1, Start: 16, End: 32}},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	var s sampler
+	if err := encoding.Unmarshal(data, &s); err != nil {
+		panic(err)
+	}
+
+	fmt.Println(len(data), s.Name, s.Loops)
+	// Output:
+	// 29 Kick [{1 16 32}]
+}
+
+type numbers struct {
+	U8  uint8
+	I8  int8
+	U16 uint16
+	I16 int16
+	U32 uint32
+	I32 int32
+	U64 uint64
+	I64 int64
+	F32 float32
+	F64 float64
+}
+
+func TestNumbers(t *testing.T) {
+	n := numbers{
+		U8: 0xFF, I8: -2,
+		U16: 0x0102, I16: -3,
+		U32: 0x01020304, I32: -4,
+		U64: 0x0102030405060708, I64: -5,
+		F32: 1.5, F64: -0.25,
+	}
+
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		data, err := encoding.MarshalOrder(n, order)
+		assert.NoError(t, err)
+		assert.Len(t, data, 1+1+2+2+4+4+8+8+4+8)
+		assert.Equal(t, uint16(0x0102), order.Uint16(data[2:]))
+
+		var decoded numbers
+		assert.NoError(t, encoding.UnmarshalOrder(data, &decoded, order))
+		assert.Equal(t, n, decoded)
+	}
+}
+
+func TestFourCC(t *testing.T) {
+	type header struct {
+		ID       goriffa.FileType
+		Channels uint16
+	}
+
+	h := header{ID: goriffa.FileType(internal.StringMust4Byte("WAVE")), Channels: 2}
+	data, err := encoding.Marshal(&h)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("WAVE\x02\x00"), data)
+
+	var decoded header
+	assert.NoError(t, encoding.Unmarshal(data, &decoded))
+	assert.Equal(t, h, decoded)
+}
+
+func TestStrings(t *testing.T) {
+	type strings struct {
+		Fixed  string `riff:"size=6"`
+		Zero   string `riff:"zstring"`
+		Prefix string `riff:"prefix=uint8"`
+		Bytes  []byte `riff:"prefix=uint16"`
+		Rest   string `riff:"rest"`
+	}
+
+	s := strings{Fixed: "abc", Zero: "de", Prefix: "fgh", Bytes: []byte{1, 2}, Rest: "tail"}
+	data, err := encoding.Marshal(s)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("abc\x00\x00\x00de\x00\x03fgh\x02\x00\x01\x02tail"), data)
+
+	var decoded strings
+	assert.NoError(t, encoding.Unmarshal(data, &decoded))
+	assert.Equal(t, s, decoded)
+}
+
+func TestNested(t *testing.T) {
+	type point struct {
+		X, Y int16
+	}
+	type shape struct {
+		Origin  point
+		Corners [2]point
+		Points  []point `riff:"rest"`
+		skipped int
+		Skipped int `riff:"-"`
+	}
+
+	s := shape{
+		Origin:  point{1, 2},
+		Corners: [2]point{{3, 4}, {5, 6}},
+		Points:  []point{{7, 8}, {9, 10}},
+		skipped: 1,
+		Skipped: 2,
+	}
+	data, err := encoding.Marshal(s)
+	assert.NoError(t, err)
+	assert.Len(t, data, 5*4)
+
+	var decoded shape
+	assert.NoError(t, encoding.Unmarshal(data, &decoded))
+	s.skipped, s.Skipped = 0, 0
+	assert.Equal(t, s, decoded)
+}
+
+func TestUnmarshalLeftover(t *testing.T) {
+	var v struct{ A uint16 }
+	assert.NoError(t, encoding.Unmarshal([]byte{1, 0, 2, 3}, &v))
+	assert.Equal(t, uint16(1), v.A)
+}
+
+func TestUnmarshalBadData(t *testing.T) {
+	type zstring struct {
+		A string `riff:"zstring"`
+	}
+	type prefixed struct {
+		A []byte `riff:"prefix=uint8"`
+	}
+	type rest struct {
+		A []uint16 `riff:"rest"`
+	}
+
+	for name, tc := range map[string]struct {
+		data []byte
+		v    interface{}
+	}{
+		"short":         {[]byte{1, 2, 3}, &struct{ A uint32 }{}},
+		"unterminated":  {[]byte("abc"), &zstring{}},
+		"long prefix":   {[]byte{4, 'a', 'b'}, &prefixed{}},
+		"short element": {[]byte{1, 0, 2}, &rest{}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, encoding.Unmarshal(tc.data, tc.v), goriffa.ErrBadChunk)
+		})
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	type badSize struct {
+		A string `riff:"size=x"`
+	}
+	type badPrefix struct {
+		A string `riff:"prefix=int8"`
+	}
+	type unknownTag struct {
+		A string `riff:"utf16"`
+	}
+	type fixed struct {
+		A string `riff:"size=2"`
+	}
+	type zstring struct {
+		A string `riff:"zstring"`
+	}
+	type prefixed struct {
+		A []byte `riff:"prefix=uint8"`
+	}
+
+	for name, v := range map[string]interface{}{
+		"not a struct":  42,
+		"nil pointer":   (*numbers)(nil),
+		"untagged":      struct{ A string }{"a"},
+		"bad size":      badSize{"a"},
+		"bad prefix":    badPrefix{"a"},
+		"unknown tag":   unknownTag{"a"},
+		"unsupported":   struct{ A int }{1},
+		"too long":      fixed{"abc"},
+		"NUL":           zstring{"a\x00b"},
+		"prefix limit":  prefixed{make([]byte, 256)},
+		"untagged list": struct{ A []uint16 }{[]uint16{1}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := encoding.Marshal(v)
+			assert.ErrorIs(t, err, encoding.ErrUnsupported)
+		})
+	}
+
+	var s sampler
+	assert.ErrorIs(t, encoding.Unmarshal(nil, s), encoding.ErrUnsupported)
+	assert.ErrorIs(t, encoding.Unmarshal(nil, &struct{ A string }{}), encoding.ErrUnsupported)
+}
+
+func TestUnsupportedTags(t *testing.T) {
+	type sizedInt struct {
+		A uint32 `riff:"size=4"`
+	}
+	type prefixedArray struct {
+		A [2]uint16 `riff:"prefix=uint8"`
+	}
+	type restStruct struct {
+		A struct{ B uint8 } `riff:"rest"`
+	}
+	type zstringSlice struct {
+		A []uint16 `riff:"zstring"`
+	}
+	type sizedSlice struct {
+		A []uint16 `riff:"size=4"`
+	}
+	type restFirst struct {
+		A []byte `riff:"rest"`
+		B uint8
+	}
+	type restTwice struct {
+		A string `riff:"rest"`
+		B string `riff:"rest"`
+	}
+
+	for name, v := range map[string]interface{}{
+		"sized int":      &sizedInt{},
+		"prefixed array": &prefixedArray{},
+		"rest struct":    &restStruct{},
+		"zstring slice":  &zstringSlice{},
+		"sized slice":    &sizedSlice{},
+		"rest first":     &restFirst{},
+		"rest twice":     &restTwice{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := encoding.Marshal(v)
+			assert.ErrorIs(t, err, encoding.ErrUnsupported)
+			assert.ErrorIs(t, encoding.Unmarshal(make([]byte, 8), v), encoding.ErrUnsupported)
+		})
+	}
+}
+
+func TestUnmarshalEmptyElements(t *testing.T) {
+	type empty struct {
+		Skipped uint32 `riff:"-"`
+	}
+	type rest struct {
+		A []empty `riff:"rest"`
+	}
+	type prefixed struct {
+		A []empty `riff:"prefix=uint32"`
+	}
+
+	assert.ErrorIs(t, encoding.Unmarshal([]byte{1, 2, 3}, &rest{}), encoding.ErrUnsupported)
+	assert.ErrorIs(t, encoding.Unmarshal([]byte{3, 0, 0, 0, 1, 2, 3}, &prefixed{}), encoding.ErrUnsupported)
+
+	// The count cannot exceed the data left.
+	assert.ErrorIs(t, encoding.Unmarshal([]byte{0xFF, 0xFF, 0xFF, 0xFF, 1}, &prefixed{}), goriffa.ErrBadChunk)
+
+	// Without elements, there's nothing to decode.
+	var v rest
+	assert.NoError(t, encoding.Unmarshal(nil, &v))
+	assert.Empty(t, v.A)
+}
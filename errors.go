@@ -16,4 +16,9 @@ var (
 	// ErrBadChunk is returned when an attempt is made to
 	// parse a chunk but the data is in an invalid format.
 	ErrBadChunk error = internal.ErrBadChunk
+
+	// ErrLimitExceeded is returned when RIFF data exceeds
+	// a limit imposed on the reader, such as a maximum
+	// chunk size.
+	ErrLimitExceeded error = internal.ErrLimitExceeded
 )
@@ -0,0 +1,106 @@
+// Package info defines the metadata held by "INFO" lists,
+// e.g. a file's title ("INAM") or artist ("IART"), which
+// may be part of any kind of RIFF file.
+//
+// Importing package info registers a codec (see package
+// codec) decoding "INFO" lists as Info.
+package info
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/standoffvenus/goriffa/codec"
+	"github.com/standoffvenus/goriffa/internal"
+)
+
+// ListTypeInfo is the list type of "INFO" lists.
+var ListTypeInfo internal.FileType = internal.Must4Byte([]byte("INFO"))
+
+// Info holds the metadata of an "INFO" list, keyed by the
+// FOURCC of the chunk holding it (e.g. "INAM" for the
+// title). Values are stored without their terminating
+// NUL byte.
+type Info map[string]string
+
+func init() {
+	codec.RegisterList(codec.AnyFileType, ListTypeInfo, codec.Codec{
+		Decode: decode,
+		Encode: encode,
+	})
+}
+
+// decode decodes the data of an "INFO" list, including
+// its list type.
+func decode(data []byte, order binary.ByteOrder) (interface{}, error) {
+	info := make(Info)
+	for rest := data[4:]; len(rest) > 0; {
+		if len(rest) < internal.LengthChunkHeader {
+			return nil, fmt.Errorf("%w: %d bytes too few to hold a chunk", internal.ErrCorrupted, len(rest))
+		}
+
+		id := string(rest[:4])
+		size := int64(order.Uint32(rest[4:]))
+		rest = rest[internal.LengthChunkHeader:]
+		if size > int64(len(rest)) {
+			return nil, fmt.Errorf("%w: chunk %q outlasts its list by %d bytes", internal.ErrCorrupted, id, size-int64(len(rest)))
+		}
+
+		info[id] = trimNUL(rest[:size])
+		if padded := internal.PaddedLength(size); padded <= int64(len(rest)) {
+			size = padded
+		}
+		rest = rest[size:]
+	}
+
+	return info, nil
+}
+
+// encode encodes an Info (or a map[string]string) as the
+// data of an "INFO" list. Chunks are ordered by FOURCC.
+func encode(v interface{}, order binary.ByteOrder) ([]byte, error) {
+	var info Info
+	switch concrete := v.(type) {
+	case Info:
+		info = concrete
+	case map[string]string:
+		info = concrete
+	default:
+		return nil, fmt.Errorf("%w: %T is no Info", codec.ErrUnsupportedValue, v)
+	}
+
+	ids := make([]string, 0, len(info))
+	for id := range info {
+		if len(id) != 4 {
+			return nil, fmt.Errorf("%w: %q is no FOURCC", codec.ErrUnsupportedValue, id)
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	data := append([]byte(nil), ListTypeInfo[:]...)
+	for _, id := range ids {
+		value := append([]byte(info[id]), 0)
+
+		var size [4]byte
+		order.PutUint32(size[:], uint32(len(value)))
+		data = append(data, id...)
+		data = append(data, size[:]...)
+		data = append(data, internal.Pad(value)...)
+	}
+
+	return data, nil
+}
+
+// trimNUL returns the string held by b, up to its first
+// NUL byte.
+func trimNUL(b []byte) string {
+	for i, c := range b {
+		if c == 0 {
+			return string(b[:i])
+		}
+	}
+
+	return string(b)
+}
@@ -0,0 +1,75 @@
+package info_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/codec"
+	"github.com/standoffvenus/goriffa/info"
+	"github.com/standoffvenus/goriffa/internal"
+	"github.com/standoffvenus/goriffa/internal/test"
+	"github.com/standoffvenus/goriffa/wave"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+	c := goriffa.Chunk{
+		Identifier: goriffa.FourCCList,
+		Data: []byte("INFO" +
+			"INAM\x05\x00\x00\x00Song\x00\x00" +
+			"IART\x04\x00\x00\x00Band" +
+			"ICMT\x03\x00\x00\x00odd\x00"),
+	}
+
+	// INFO lists may be part of any kind of RIFF file.
+	for _, fileType := range []internal.FileType{wave.FileTypeWavefile, test.FileType} {
+		v, err := codec.Decode(fileType, c, binary.LittleEndian)
+		assert.NoError(t, err)
+		assert.Equal(t, info.Info{"INAM": "Song", "IART": "Band", "ICMT": "odd"}, v)
+	}
+}
+
+func TestDecodeUnpadded(t *testing.T) {
+	// The last chunk of a list may lack its padding byte.
+	c := goriffa.Chunk{Identifier: goriffa.FourCCList, Data: []byte("INFOICMT\x03\x00\x00\x00odd")}
+
+	v, err := codec.Decode(test.FileType, c, binary.LittleEndian)
+	assert.NoError(t, err)
+	assert.Equal(t, info.Info{"ICMT": "odd"}, v)
+}
+
+func TestDecodeCorrupted(t *testing.T) {
+	for _, data := range []string{
+		"INFOINAM",
+		"INFOINAM\x08\x00\x00\x00Song",
+	} {
+		_, err := codec.Decode(test.FileType, goriffa.Chunk{Identifier: goriffa.FourCCList, Data: []byte(data)}, binary.LittleEndian)
+		assert.ErrorIs(t, err, goriffa.ErrCorrupted, data)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	c, err := codec.EncodeList(test.FileType, info.ListTypeInfo, info.Info{"INAM": "Song", "IART": "Band"}, binary.LittleEndian)
+	assert.NoError(t, err)
+	assert.Equal(t, goriffa.Chunk{
+		Identifier: goriffa.FourCCList,
+		Data:       []byte("INFOIART\x05\x00\x00\x00Band\x00\x00INAM\x05\x00\x00\x00Song\x00\x00"),
+	}, c)
+
+	c, err = codec.EncodeList(test.FileType, info.ListTypeInfo, map[string]string{"ICMT": "x"}, binary.BigEndian)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("INFOICMT\x00\x00\x00\x02x\x00"), c.Data)
+
+	v, err := codec.Decode(test.FileType, c, binary.BigEndian)
+	assert.NoError(t, err)
+	assert.Equal(t, info.Info{"ICMT": "x"}, v)
+}
+
+func TestEncodeErrors(t *testing.T) {
+	_, err := codec.EncodeList(test.FileType, info.ListTypeInfo, info.Info{"NAME": "ok", "TITLE": "Song"}, binary.LittleEndian)
+	assert.ErrorIs(t, err, codec.ErrUnsupportedValue)
+
+	_, err = codec.EncodeList(test.FileType, info.ListTypeInfo, []string{"Song"}, binary.LittleEndian)
+	assert.ErrorIs(t, err, codec.ErrUnsupportedValue)
+}
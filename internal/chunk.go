@@ -1,9 +1,16 @@
 package internal
 
+import "fmt"
+
 // LengthChunkHeader represents the length (in bytes) of
 // a RIFF chunk header.
 const LengthChunkHeader int = 8
 
+// LengthListHeader represents the length (in bytes) of
+// a RIFF list header - that is, the chunk header followed
+// by the 4-byte list type.
+const LengthListHeader int = LengthChunkHeader + 4
+
 // EmptyBytes holds an empty 4 bytes.
 var EmptyBytes fourBytes
 
@@ -28,6 +35,25 @@ type Chunk struct {
 	Data []byte
 }
 
+// ChunkHeader represents the header of a RIFF chunk,
+// i.e. the chunk's FOURCC identifier and the length
+// of its data.
+type ChunkHeader struct {
+	// The chunk's FOURCC identifier.
+	Identifier FourCC
+
+	// Size holds the length of the chunk
+	// data, excluding any padding.
+	Size int64
+}
+
+// ByteLength will return how many bytes the chunk
+// described by this header is once formatted
+// (including header and padding).
+func (h ChunkHeader) ByteLength() int64 {
+	return 8 + PaddedLength(h.Size)
+}
+
 // ByteLength will return how many bytes this
 // chunk would be once formatted (including
 // header and padding)
@@ -35,6 +61,20 @@ func (c Chunk) ByteLength() int64 {
 	return 8 + PaddedLength(int64(len(c.Data)))
 }
 
+// ParseFourCC will parse the provided FOURCC, padding it
+// with spaces if it's shorter than four characters (so
+// "fmt" is parsed as "fmt ").
+func ParseFourCC(s string) (FourCC, error) {
+	var id FourCC
+	if s == "" || len(s) > len(id) {
+		return id, fmt.Errorf("invalid FOURCC %q: must be 1 to 4 bytes long", s)
+	}
+
+	copy(id[:], s+"   ")
+
+	return id, nil
+}
+
 // String will return the string representation
 // of the FOURCC.
 func (cc FourCC) String() string {
@@ -20,3 +20,27 @@ func TestByteLength(t *testing.T) {
 	// m padding bytes
 	assert.Equal(t, int64(8+len(c.Data)+len(c.Data)%2), c.ByteLength())
 }
+
+func TestChunkHeaderByteLength(t *testing.T) {
+	h := internal.ChunkHeader{
+		Identifier: goriffa.FourCCData,
+		Size:       3,
+	}
+
+	assert.Equal(t, internal.Chunk{Data: make([]byte, 3)}.ByteLength(), h.ByteLength())
+}
+
+func TestParseFourCC(t *testing.T) {
+	id, err := internal.ParseFourCC("fmt")
+	assert.NoError(t, err)
+	assert.Equal(t, goriffa.FourCCFormat, id)
+
+	id, err = internal.ParseFourCC("data")
+	assert.NoError(t, err)
+	assert.Equal(t, goriffa.FourCCData, id)
+
+	for _, s := range []string{"", "datas"} {
+		_, err := internal.ParseFourCC(s)
+		assert.Error(t, err, s)
+	}
+}
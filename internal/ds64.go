@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// LengthDS64 represents the length (in bytes) of a
+// "ds64" chunk's data, excluding its table.
+const LengthDS64 int = 28
+
+// lengthDS64Entry represents the length (in bytes) of
+// a single entry of a "ds64" chunk's table.
+const lengthDS64Entry int = 12
+
+// DS64 represents the data of a "ds64" chunk, which
+// holds the 64-bit sizes of an RF64 (or BW64) file.
+// RF64 files set their 32-bit size fields to 0xFFFFFFFF
+// whenever the real size is held by the ds64 chunk.
+//
+// All fields are little-endian, formatted as below:
+//  RIFF size (8 bytes), data size (8 bytes),
+//  sample count (8 bytes), table length (4 bytes),
+//  table entries (12 bytes each)
+type DS64 struct {
+	// RIFFSize holds the size of the RIFF data.
+	RIFFSize uint64
+
+	// DataSize holds the size of the "data"
+	// chunk's data.
+	DataSize uint64
+
+	// SampleCount holds the number of samples
+	// (per channel) in the "data" chunk.
+	SampleCount uint64
+
+	// Table holds the sizes of any other
+	// chunks larger than 4 GB.
+	Table []DS64Entry
+}
+
+// DS64Entry represents an entry of a "ds64"
+// chunk's table.
+type DS64Entry struct {
+	// The chunk's FOURCC identifier.
+	Identifier FourCC
+
+	// Size holds the length of the chunk's data.
+	Size uint64
+}
+
+// ParseDS64 will parse the data of a "ds64" chunk.
+// If the data is too short, ErrBadChunk is returned.
+func ParseDS64(b []byte) (DS64, error) {
+	var ds64 DS64
+	if len(b) < LengthDS64 {
+		return ds64, fmt.Errorf("%w: ds64 chunk too short (%d)", ErrBadChunk, len(b))
+	}
+
+	buffer := bytes.NewReader(b)
+	ds64.RIFFSize = ReadLittleEndianUInt64(buffer)
+	ds64.DataSize = ReadLittleEndianUInt64(buffer)
+	ds64.SampleCount = ReadLittleEndianUInt64(buffer)
+	tableLength := ReadLittleEndianUInt32(buffer)
+	if int64(tableLength)*int64(lengthDS64Entry) > int64(buffer.Len()) {
+		return ds64, fmt.Errorf("%w: ds64 table too long (%d entries)", ErrBadChunk, tableLength)
+	}
+
+	ds64.Table = make([]DS64Entry, tableLength)
+	for idx := range ds64.Table {
+		if _, err := Read(buffer, ds64.Table[idx].Identifier[:]); err != nil {
+			Panic(err)
+		}
+		ds64.Table[idx].Size = ReadLittleEndianUInt64(buffer)
+	}
+
+	return ds64, nil
+}
+
+// Bytes will return the data of the "ds64" chunk
+// as formatted within a RIFF file.
+func (ds64 DS64) Bytes() []byte {
+	b := make([]byte, 0, LengthDS64+len(ds64.Table)*lengthDS64Entry)
+	b = append(b, LittleEndianUInt64Bytes(ds64.RIFFSize)...)
+	b = append(b, LittleEndianUInt64Bytes(ds64.DataSize)...)
+	b = append(b, LittleEndianUInt64Bytes(ds64.SampleCount)...)
+	b = append(b, LittleEndianUInt32Bytes(uint32(len(ds64.Table)))...)
+	for _, entry := range ds64.Table {
+		b = append(b, entry.Identifier[:]...)
+		b = append(b, LittleEndianUInt64Bytes(entry.Size)...)
+	}
+
+	return b
+}
+
+// ChunkSize will return the 64-bit size of the chunk
+// with the provided identifier, if held by the ds64
+// chunk.
+func (ds64 DS64) ChunkSize(identifier FourCC) (uint64, bool) {
+	if identifier == FourCC(StringMust4Byte("data")) {
+		return ds64.DataSize, true
+	}
+
+	for _, entry := range ds64.Table {
+		if entry.Identifier == identifier {
+			return entry.Size, true
+		}
+	}
+
+	return 0, false
+}
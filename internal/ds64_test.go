@@ -0,0 +1,56 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDS64(t *testing.T) {
+	var b []byte
+	b = append(b, internal.LittleEndianUInt64Bytes(1<<33)...)
+	b = append(b, internal.LittleEndianUInt64Bytes(1<<32)...)
+	b = append(b, internal.LittleEndianUInt64Bytes(42)...)
+	b = append(b, internal.LittleEndianUInt32Bytes(1)...)
+	b = append(b, goriffa.FourCCSMPL[:]...)
+	b = append(b, internal.LittleEndianUInt64Bytes(1<<34)...)
+
+	ds64, err := internal.ParseDS64(b)
+	assert.NoError(t, err)
+	assert.Equal(t, internal.DS64{
+		RIFFSize:    1 << 33,
+		DataSize:    1 << 32,
+		SampleCount: 42,
+		Table: []internal.DS64Entry{
+			{Identifier: goriffa.FourCCSMPL, Size: 1 << 34},
+		},
+	}, ds64)
+
+	assert.Equal(t, b, ds64.Bytes())
+
+	dataSize, dataOK := ds64.ChunkSize(goriffa.FourCCData)
+	assert.True(t, dataOK)
+	assert.Equal(t, uint64(1<<32), dataSize)
+
+	smplSize, smplOK := ds64.ChunkSize(goriffa.FourCCSMPL)
+	assert.True(t, smplOK)
+	assert.Equal(t, uint64(1<<34), smplSize)
+
+	_, fmtOK := ds64.ChunkSize(goriffa.FourCCFormat)
+	assert.False(t, fmtOK)
+}
+
+func TestParseDS64TooShort(t *testing.T) {
+	_, err := internal.ParseDS64(make([]byte, internal.LengthDS64-1))
+	assert.ErrorIs(t, err, goriffa.ErrBadChunk)
+}
+
+func TestParseDS64TableTooLong(t *testing.T) {
+	b := make([]byte, internal.LengthDS64)
+	copy(b[24:], internal.LittleEndianUInt32Bytes(1))
+
+	_, err := internal.ParseDS64(b)
+	assert.ErrorIs(t, err, goriffa.ErrBadChunk)
+}
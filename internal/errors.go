@@ -24,6 +24,11 @@ var (
 	// ErrBadChunk is returned when an attempt is made to
 	// parse a chunk but the data is in an invalid format.
 	ErrBadChunk error = errors.New("invalid chunk")
+
+	// ErrLimitExceeded is returned when RIFF data exceeds
+	// a limit imposed on the reader, such as a maximum
+	// chunk size.
+	ErrLimitExceeded error = errors.New("limit exceeded")
 )
 
 // Wrap returns a new error with the given message,
@@ -175,6 +175,22 @@ func ReadLittleEndianUInt32(r io.ByteReader) uint32 {
 	return binary.LittleEndian.Uint32(bytes)
 }
 
+// ReadLittleEndianUInt64 will read a uint64 from
+// the reader. If an error is returned, the function
+// will panic.
+func ReadLittleEndianUInt64(r io.ByteReader) uint64 {
+	bytes := make([]byte, 0, 8)
+	for i := 0; i < cap(bytes); i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			Panic(err)
+		}
+		bytes = append(bytes, b)
+	}
+
+	return binary.LittleEndian.Uint64(bytes)
+}
+
 // LittleEndianUInt16Bytes is shorthand for
 //	var uint16Bytes [2]byte
 //	binary.LittleEndian.PutUint16(uint16Bytes[:], u)
@@ -194,3 +210,23 @@ func LittleEndianUInt32Bytes(u uint32) []byte {
 
 	return uint32Bytes[:]
 }
+
+// LittleEndianUInt64Bytes is shorthand for
+//	var uint64Bytes [8]byte
+//	binary.LittleEndian.PutUint64(uint64Bytes[:], u)
+func LittleEndianUInt64Bytes(u uint64) []byte {
+	var uint64Bytes [8]byte
+	binary.LittleEndian.PutUint64(uint64Bytes[:], u)
+
+	return uint64Bytes[:]
+}
+
+// UInt32Bytes is shorthand for
+//	var uint32Bytes [4]byte
+//	order.PutUint32(uint32Bytes[:], u)
+func UInt32Bytes(order binary.ByteOrder, u uint32) []byte {
+	var uint32Bytes [4]byte
+	order.PutUint32(uint32Bytes[:], u)
+
+	return uint32Bytes[:]
+}
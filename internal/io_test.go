@@ -2,6 +2,7 @@ package internal_test
 
 import (
 	"bytes"
+	"encoding/binary"
 	"errors"
 	"io"
 	"testing"
@@ -179,6 +180,11 @@ func TestWriteAtUnderflow(t *testing.T) {
 	writer.AssertExpectations(t)
 }
 
+func TestUInt32Bytes(t *testing.T) {
+	assert.Equal(t, []byte{1, 2, 3, 4}, internal.UInt32Bytes(binary.BigEndian, 0x01020304))
+	assert.Equal(t, internal.LittleEndianUInt32Bytes(0x01020304), internal.UInt32Bytes(binary.LittleEndian, 0x01020304))
+}
+
 func join(t *testing.T, b [][]byte) []byte {
 	var buffer bytes.Buffer
 	for _, slice := range b {
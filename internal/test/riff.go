@@ -0,0 +1,65 @@
+package test
+
+import (
+	"bytes"
+
+	"github.com/standoffvenus/goriffa/internal"
+)
+
+// RIFF returns RIFF data of type FileType holding the
+// provided content.
+func RIFF(content ...[]byte) []byte {
+	return RIFFOf(string(FileType[:]), content...)
+}
+
+// RIFFOf returns RIFF data of the provided type holding
+// the provided content.
+func RIFFOf(fileType string, content ...[]byte) []byte {
+	return RIFFWithSize(fileType, uint32(4+len(bytes.Join(content, nil))), content...)
+}
+
+// RIFFWithSize returns RIFF data of the provided type
+// holding the provided content, whose header holds the
+// provided size regardless of the content.
+func RIFFWithSize(fileType string, size uint32, content ...[]byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	buf.Write(internal.LittleEndianUInt32Bytes(size))
+	buf.WriteString(fileType)
+	buf.Write(bytes.Join(content, nil))
+
+	return buf.Bytes()
+}
+
+// List returns a list of the provided type holding the
+// provided chunks.
+func List(listType string, chunks ...[]byte) []byte {
+	data := append([]byte(listType), bytes.Join(chunks, nil)...)
+
+	return append(Header("LIST", len(data)), data...)
+}
+
+// Chunk returns a chunk holding the provided data,
+// followed by a padding byte if the data is of odd
+// length.
+func Chunk(identifier, data string) []byte {
+	return append(Header(identifier, len(data)), internal.Pad([]byte(data))...)
+}
+
+// Unpadded returns a chunk holding the provided data,
+// lacking any padding byte.
+func Unpadded(identifier, data string) []byte {
+	return append(Header(identifier, len(data)), data...)
+}
+
+// Header returns a chunk header holding the provided
+// size.
+func Header(identifier string, size int) []byte {
+	return append([]byte(identifier), internal.LittleEndianUInt32Bytes(uint32(size))...)
+}
+
+// FourCC returns the provided 4-character string as a
+// FOURCC.
+func FourCC(s string) internal.FourCC {
+	return internal.FourCC(internal.StringMust4Byte(s))
+}
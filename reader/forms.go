@@ -0,0 +1,121 @@
+package reader
+
+import (
+	"errors"
+	"io"
+
+	"github.com/standoffvenus/goriffa/internal"
+)
+
+// Forms reads RIFF data made up of several RIFF forms
+// stored back to back, such as OpenDML AVI files - where
+// the "AVI " form is followed by any number of "AVIX"
+// forms - or the output of some capture tools.
+//
+// Forms is NOT concurrent-safe.
+type Forms struct {
+	r       io.Reader
+	options []Option
+	form    *Reader
+	done    bool
+}
+
+// NewForms creates a new Forms reading RIFF forms from
+// the provided io.Reader. Every form is read by a Reader
+// configured with the provided options, just like New.
+func NewForms(r io.Reader, options ...Option) *Forms {
+	return &Forms{
+		r:       r,
+		options: options,
+	}
+}
+
+// Next will skip whatever remains of the current form and
+// return a Reader over the next one, whose Identifier,
+// FileType and Size are the form's own. Offsets (see
+// Reader.Offset) are relative to the beginning of the
+// stream rather than of the form. Just like a list
+// Reader, the Reader of a form must no longer be used
+// once Next is called again.
+//
+// If there are no more forms, io.EOF is returned. If the
+// stream holds anything but RIFF forms, goriffa.ErrCorrupted
+// is returned - unless Lenient is used, in which case
+// io.EOF is returned instead.
+func (f *Forms) Next() (*Reader, error) {
+	if f.done {
+		return nil, io.EOF
+	}
+
+	var offset int64
+	if f.form != nil {
+		if err := f.form.finish(); err != nil {
+			return nil, err
+		}
+		offset = f.form.offset()
+
+		// Whatever follows a truncated form - or one lasting
+		// until the end of the data - is lost.
+		if f.form.truncated || f.form.overrun {
+			f.done = true
+
+			return nil, io.EOF
+		}
+	}
+
+	var header [internal.LengthListHeader]byte
+	n, err := io.ReadFull(f.r, header[:])
+	if n == 0 && errors.Is(err, io.EOF) {
+		f.done = true
+
+		return nil, io.EOF
+	}
+
+	var form *Reader
+	if err == nil {
+		var size [4]byte
+		copy(size[:], header[4:])
+		form, err = newReader(f.r,
+			internal.FourCC(internal.Must4Byte(header[:4])),
+			size,
+			internal.Must4Byte(header[8:]),
+			offset,
+			append([]Option{asForm}, f.options...))
+	}
+	if err != nil {
+		f.done = true
+		if f.form != nil && f.form.lenient {
+			return nil, io.EOF
+		}
+
+		return nil, wrap(err)
+	}
+
+	// Lenient Readers read through a buffer, which must be
+	// shared by all forms.
+	f.r = form.r
+	f.form = form
+
+	return form, nil
+}
+
+// asForm marks a Reader as the Reader of a form.
+func asForm(r *Reader) {
+	r.form = true
+}
+
+// finish will skip whatever remains of the RIFF data,
+// including its padding byte.
+func (r *Reader) finish() error {
+	r.peeked = nil
+	if r.truncated || r.overrun {
+		return nil
+	}
+
+	err := r.skip(internal.PaddedLength(r.size) - r.bytesRead)
+	if r.lenient {
+		return r.recover(err)
+	}
+
+	return err
+}
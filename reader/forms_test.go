@@ -0,0 +1,173 @@
+package reader_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/internal"
+	"github.com/standoffvenus/goriffa/internal/test"
+	"github.com/standoffvenus/goriffa/reader"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForms(t *testing.T) {
+	first := []goriffa.Chunk{
+		{Identifier: fourCC("hdrl"), Data: []byte("header")},
+		{Identifier: fourCC("movi"), Data: []byte("odd")},
+	}
+	second := []goriffa.Chunk{
+		{Identifier: fourCC("movi"), Data: []byte("more frames")},
+	}
+	data := append(form("AVI ", first...), form("AVIX", second...)...)
+
+	for name, r := range map[string]io.Reader{
+		"seeker":     bytes.NewReader(data),
+		"not seeker": struct{ io.Reader }{bytes.NewReader(data)},
+	} {
+		forms := reader.NewForms(r)
+
+		f, err := forms.Next()
+		assert.NoError(t, err, name)
+		assert.Equal(t, internal.FileType(internal.StringMust4Byte("AVI ")), f.FileType(), name)
+		chunks, err := f.ReadToEnd()
+		assert.NoError(t, err, name)
+		assert.Equal(t, first, chunks, name)
+
+		f, err = forms.Next()
+		assert.NoError(t, err, name)
+		assert.Equal(t, internal.FileType(internal.StringMust4Byte("AVIX")), f.FileType(), name)
+		assert.Equal(t, uint32(4+20), f.Size(), name)
+
+		// Offsets are relative to the beginning of the stream.
+		_, err = f.NextHeader()
+		assert.NoError(t, err, name)
+		assert.Equal(t, int64(len(form("AVI ", first...))+20), f.Offset(), name)
+
+		chunks, err = f.ReadToEnd()
+		assert.NoError(t, err, name)
+		assert.Equal(t, second, chunks, name)
+
+		_, err = forms.Next()
+		assert.ErrorIs(t, err, io.EOF, name)
+		_, err = forms.Next()
+		assert.ErrorIs(t, err, io.EOF, name)
+	}
+}
+
+func TestFormsSkipUnread(t *testing.T) {
+	c := goriffa.Chunk{Identifier: fourCC("abcd"), Data: []byte("data")}
+	data := bytes.Join([][]byte{
+		form("frst", c, c),
+		form("scnd", c),
+		form("thrd", c),
+	}, nil)
+
+	forms := reader.NewForms(struct{ io.Reader }{bytes.NewReader(data)})
+
+	f, err := forms.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, internal.FileType(internal.StringMust4Byte("frst")), f.FileType())
+
+	// Leave the first form partially read.
+	_, err = f.NextHeader()
+	assert.NoError(t, err)
+
+	f, err = forms.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, internal.FileType(internal.StringMust4Byte("scnd")), f.FileType())
+
+	f, err = forms.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, internal.FileType(internal.StringMust4Byte("thrd")), f.FileType())
+	chunks, err := f.ReadToEnd()
+	assert.NoError(t, err)
+	assert.Equal(t, []goriffa.Chunk{c}, chunks)
+
+	_, err = forms.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestFormsSingle(t *testing.T) {
+	wav, details := test.WAV()
+	forms := reader.NewForms(wav)
+
+	f, err := forms.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, details.FileType(), f.FileType())
+
+	_, err = forms.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestFormsEmpty(t *testing.T) {
+	_, err := reader.NewForms(bytes.NewReader(nil)).Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestFormsTrailingData(t *testing.T) {
+	c := goriffa.Chunk{Identifier: fourCC("abcd"), Data: []byte("data")}
+
+	for _, trailing := range [][]byte{[]byte("short"), []byte("not a RIFF form")} {
+		data := append(form("frst", c), trailing...)
+
+		forms := reader.NewForms(bytes.NewReader(data))
+		_, err := forms.Next()
+		assert.NoError(t, err)
+		_, err = forms.Next()
+		assert.ErrorIs(t, err, goriffa.ErrCorrupted)
+
+		forms = reader.NewForms(bytes.NewReader(data), reader.Lenient())
+		_, err = forms.Next()
+		assert.NoError(t, err)
+		_, err = forms.Next()
+		assert.ErrorIs(t, err, io.EOF)
+	}
+}
+
+func TestFormsLenient(t *testing.T) {
+	c := goriffa.Chunk{Identifier: fourCC("abcd"), Data: []byte("data")}
+
+	// The second form was cut short, e.g. by a crash.
+	second := form("scnd", c, c)
+	data := append(form("frst", c), second[:len(second)-2]...)
+
+	forms := reader.NewForms(bytes.NewReader(data), reader.Lenient())
+
+	f, err := forms.Next()
+	assert.NoError(t, err)
+	chunks, err := f.ReadToEnd()
+	assert.NoError(t, err)
+	assert.Equal(t, []goriffa.Chunk{c}, chunks)
+	assert.Empty(t, f.Warnings())
+
+	f, err = forms.Next()
+	assert.NoError(t, err)
+	chunks, err = f.ReadToEnd()
+	assert.NoError(t, err)
+	assert.Equal(t, []goriffa.Chunk{c, {Identifier: c.Identifier, Data: c.Data[:2]}}, chunks)
+	assert.Equal(t, []reader.Warning{{
+		Code:    reader.WarningTruncated,
+		Offset:  int64(len(form("frst", c))) + 24,
+		Message: `chunk "abcd" truncated to 2 of 4 bytes`,
+	}}, f.Warnings())
+
+	_, err = forms.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func form(fileType string, chunks ...goriffa.Chunk) []byte {
+	var data []byte
+	for _, c := range chunks {
+		data = append(data, chunk(c)...)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(goriffa.FourCCRIFF[:])
+	buf.Write(internal.LittleEndianUInt32Bytes(uint32(4 + len(data))))
+	buf.WriteString(fileType)
+	buf.Write(data)
+
+	return buf.Bytes()
+}
@@ -0,0 +1,223 @@
+package reader
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/internal"
+)
+
+// Index provides random access to the chunks of RIFF
+// data. Unlike Reader, an Index reads the data through
+// an io.ReaderAt: only the chunk headers are read once,
+// when the Index is built, after which the data of any
+// chunk may be read independently of the others.
+//
+// An Index is safe for concurrent use, provided the
+// underlying io.ReaderAt is.
+type Index struct {
+	r       io.ReaderAt
+	header  *Reader
+	entries []Entry
+}
+
+// Entry describes a chunk found by an Index.
+type Entry struct {
+	internal.ChunkHeader
+
+	// Offset holds the position of the chunk's header
+	// within the RIFF data. The chunk's data begins
+	// goriffa.LengthChunkHeader bytes later.
+	Offset int64
+
+	// ListType holds the list type of the chunk if
+	// it's a list (see goriffa.IsList).
+	ListType internal.FileType
+
+	// Parent holds the position (within Entries) of
+	// the list the chunk belongs to, or -1 if the
+	// chunk is not within a list.
+	Parent int
+}
+
+// NewIndex will build an Index of the RIFF data read
+// from the provided io.ReaderAt, reading the header of
+// every chunk - including the sub-chunks of lists.
+//
+// RIFX, RF64 and BW64 data are supported, just like New.
+// If the chunk headers are inconsistent with the RIFF
+// data (e.g. a chunk outlasts its list, or the data ends
+// before a chunk header does), goriffa.ErrCorrupted will
+// be returned. As chunk data isn't read, a truncated
+// final chunk is indexed regardless; reading it via
+// ReadChunk returns goriffa.ErrCorrupted.
+//
+// The index may be configured with any number of the
+// options New accepts, e.g. to limit the number of
+// chunks indexed.
+func NewIndex(r io.ReaderAt, options ...Option) (*Index, error) {
+	header, err := New(io.NewSectionReader(r, 0, math.MaxInt64), options...)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &Index{
+		r:      r,
+		header: header,
+	}
+
+	if err := idx.scan(header.offset(), header.start+header.size, -1, 0); err != nil {
+		return nil, err
+	}
+
+	return idx, nil
+}
+
+// Entries returns every chunk indexed, in the order
+// they appear within the RIFF data. Sub-chunks follow
+// the list they belong to.
+func (idx *Index) Entries() []Entry {
+	entries := make([]Entry, len(idx.entries))
+	copy(entries, idx.entries)
+
+	return entries
+}
+
+// Lookup returns the first chunk indexed with the provided
+// identifier, at any depth. If there is no such chunk,
+// false is returned.
+func (idx *Index) Lookup(identifier internal.FourCC) (Entry, bool) {
+	for _, e := range idx.entries {
+		if e.Identifier == identifier {
+			return e, true
+		}
+	}
+
+	return Entry{}, false
+}
+
+// Open returns a reader of the data of the provided
+// chunk. For lists, the data begins with the list type.
+func (idx *Index) Open(e Entry) *io.SectionReader {
+	return io.NewSectionReader(idx.r, e.Offset+int64(internal.LengthChunkHeader), e.Size)
+}
+
+// ReadChunk will read the provided chunk in its entirety.
+// If the data is shorter than the chunk's size,
+// goriffa.ErrCorrupted is returned. If the chunk is larger
+// than the MaxChunkSize the Index was built with, a
+// LimitError is returned instead.
+func (idx *Index) ReadChunk(e Entry) (internal.Chunk, error) {
+	if err := idx.header.checkSize(e.ChunkHeader, e.Offset); err != nil {
+		return internal.Chunk{Identifier: e.Identifier}, err
+	}
+
+	section := idx.Open(e)
+	data, err := readData(func(b []byte) (int, error) {
+		return io.ReadFull(section, b)
+	}, e.Size)
+	chunk := internal.Chunk{Identifier: e.Identifier, Data: data}
+	if err != nil {
+		return chunk, wrap(err)
+	}
+
+	return chunk, nil
+}
+
+// Identifier returns the FOURCC the RIFF data begins
+// with, e.g. "RIFF" or "RIFX".
+func (idx *Index) Identifier() internal.FourCC {
+	return idx.header.Identifier()
+}
+
+// FileType returns the parsed file type for the RIFF
+// data.
+func (idx *Index) FileType() internal.FileType {
+	return idx.header.FileType()
+}
+
+// ByteOrder returns the byte order of the RIFF data's
+// size fields; see Reader.ByteOrder.
+func (idx *Index) ByteOrder() binary.ByteOrder {
+	return idx.header.ByteOrder()
+}
+
+// Size64 returns the content length as reported by the
+// RIFF data; see Reader.Size64.
+func (idx *Index) Size64() uint64 {
+	return idx.header.Size64()
+}
+
+// scan will index the chunks between the offsets start
+// and end, which belong to the list at position parent
+// nested to the provided depth.
+func (idx *Index) scan(start, end int64, parent, depth int) error {
+	for offset := start; offset < end; {
+		var b [internal.LengthChunkHeader]byte
+		if err := idx.readAt(b[:], offset); err != nil {
+			return err
+		}
+
+		header, err := idx.header.parseHeader(b)
+		if err != nil {
+			return err
+		}
+		if err := idx.header.checkChunk(offset); err != nil {
+			return err
+		}
+
+		dataOffset := offset + int64(internal.LengthChunkHeader)
+		if dataOffset+header.Size > end || dataOffset+header.Size < dataOffset {
+			return fmt.Errorf("%w: chunk %q at offset %d outlasts its list", internal.ErrCorrupted, header.Identifier, offset)
+		}
+		if idx.header.skipFiller && goriffa.IsFiller(header.Identifier) {
+			offset += header.ByteLength()
+
+			continue
+		}
+
+		idx.entries = append(idx.entries, Entry{
+			ChunkHeader: header,
+			Offset:      offset,
+			Parent:      parent,
+		})
+
+		if goriffa.IsList(header.Identifier) {
+			if header.Size < 4 {
+				return fmt.Errorf("%w: impossibly small list size (%d)", internal.ErrCorrupted, header.Size)
+			}
+
+			if err := idx.header.checkDepth(depth+1, offset); err != nil {
+				return err
+			}
+
+			position := len(idx.entries) - 1
+			if err := idx.readAt(idx.entries[position].ListType[:], dataOffset); err != nil {
+				return err
+			}
+			if err := idx.scan(dataOffset+4, dataOffset+header.Size, position, depth+1); err != nil {
+				return err
+			}
+		}
+
+		offset += header.ByteLength()
+	}
+
+	return nil
+}
+
+func (idx *Index) readAt(b []byte, offset int64) error {
+	n, err := idx.r.ReadAt(b, offset)
+	if n == len(b) {
+		return nil
+	}
+	if err == nil || errors.Is(err, io.EOF) {
+		return errCorruptedTooShort
+	}
+
+	return err
+}
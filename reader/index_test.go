@@ -0,0 +1,156 @@
+package reader_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/internal"
+	"github.com/standoffvenus/goriffa/internal/test"
+	"github.com/standoffvenus/goriffa/reader"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleIndex() {
+	idx, err := reader.NewIndex(bytes.NewReader(indexedRIFF()))
+	if err != nil {
+		panic(err)
+	}
+
+	for _, e := range idx.Entries() {
+		fmt.Printf("%q at offset %d (%d bytes), parent %d\n", e.Identifier, e.Offset, e.Size, e.Parent)
+	}
+
+	name, _ := idx.Lookup(fourCC("INAM"))
+	data, readErr := io.ReadAll(idx.Open(name))
+	if readErr != nil {
+		panic(readErr)
+	}
+
+	fmt.Printf("INAM: %s\n", data)
+	// Output: "fmt " at offset 12 (3 bytes), parent -1
+	// "LIST" at offset 24 (30 bytes), parent -1
+	// "INAM" at offset 36 (4 bytes), parent 1
+	// "ICMT" at offset 48 (5 bytes), parent 1
+	// "data" at offset 62 (2 bytes), parent -1
+	// INAM: Song
+}
+
+func TestNewIndex(t *testing.T) {
+	r := &countingReaderAt{r: bytes.NewReader(indexedRIFF())}
+	idx, err := reader.NewIndex(r)
+	assert.NoError(t, err)
+
+	assert.Equal(t, goriffa.FourCCRIFF, idx.Identifier())
+	assert.Equal(t, test.FileType, idx.FileType())
+	assert.Equal(t, uint64(len(indexedRIFF())-8), idx.Size64())
+
+	// Only the headers (and list types) were read.
+	assert.Equal(t, int64(12+5*internal.LengthChunkHeader+4), r.n)
+
+	entries := idx.Entries()
+	assert.Len(t, entries, 5)
+	assert.Equal(t, reader.Entry{
+		ChunkHeader: internal.ChunkHeader{Identifier: goriffa.FourCCList, Size: 30},
+		Offset:      24,
+		ListType:    internal.StringMust4Byte("INFO"),
+		Parent:      -1,
+	}, entries[1])
+	assert.Equal(t, 1, entries[3].Parent)
+
+	comment, readErr := idx.ReadChunk(entries[3])
+	assert.NoError(t, readErr)
+	assert.Equal(t, goriffa.Chunk{Identifier: fourCC("ICMT"), Data: []byte("great")}, comment)
+
+	_, ok := idx.Lookup(goriffa.FourCCSMPL)
+	assert.False(t, ok)
+}
+
+func TestNewIndexNested(t *testing.T) {
+	strf := goriffa.Chunk{Identifier: fourCC("strf"), Data: []byte{1, 2, 3}}
+	strl := list(internal.StringMust4Byte("strl"), strf)
+	hdrl := list(internal.StringMust4Byte("hdrl"), goriffa.Chunk{
+		Identifier: goriffa.FourCCList,
+		Data:       strl[internal.LengthChunkHeader:],
+	})
+
+	var buf bytes.Buffer
+	buf.Write(header(int64(len(hdrl))))
+	buf.Write(hdrl)
+
+	idx, err := reader.NewIndex(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+
+	entries := idx.Entries()
+	assert.Len(t, entries, 3)
+	assert.Equal(t, []int{-1, 0, 1}, []int{entries[0].Parent, entries[1].Parent, entries[2].Parent})
+
+	ch, readErr := idx.ReadChunk(entries[2])
+	assert.NoError(t, readErr)
+	assert.Equal(t, strf, ch)
+}
+
+func TestNewIndexTruncated(t *testing.T) {
+	b := indexedRIFF()
+
+	_, err := reader.NewIndex(bytes.NewReader(b[:len(b)-4]))
+	assert.ErrorIs(t, err, goriffa.ErrCorrupted)
+}
+
+func TestNewIndexChunkOutlastsList(t *testing.T) {
+	listBytes := list(internal.StringMust4Byte("INFO"),
+		goriffa.Chunk{Identifier: fourCC("INAM"), Data: []byte("name")})
+	copy(listBytes[4:], internal.LittleEndianUInt32Bytes(8))
+
+	var buf bytes.Buffer
+	buf.Write(header(int64(len(listBytes))))
+	buf.Write(listBytes)
+
+	_, err := reader.NewIndex(bytes.NewReader(buf.Bytes()))
+	assert.ErrorIs(t, err, goriffa.ErrCorrupted)
+}
+
+func TestIndexReadChunkTruncated(t *testing.T) {
+	b := indexedRIFF()
+
+	// Chunk data isn't read while indexing, so the index
+	// is built regardless of the truncated data.
+	idx, err := reader.NewIndex(bytes.NewReader(b[:len(b)-1]))
+	assert.NoError(t, err)
+
+	data, ok := idx.Lookup(goriffa.FourCCData)
+	assert.True(t, ok)
+
+	_, readErr := idx.ReadChunk(data)
+	assert.ErrorIs(t, readErr, goriffa.ErrCorrupted)
+}
+
+func indexedRIFF() []byte {
+	format := goriffa.Chunk{Identifier: goriffa.FourCCFormat, Data: []byte{1, 2, 3}}
+	info := list(internal.StringMust4Byte("INFO"),
+		goriffa.Chunk{Identifier: fourCC("INAM"), Data: []byte("Song")},
+		goriffa.Chunk{Identifier: fourCC("ICMT"), Data: []byte("great")})
+	data := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{4, 5}}
+
+	var buf bytes.Buffer
+	buf.Write(header(format.ByteLength() + int64(len(info)) + data.ByteLength()))
+	buf.Write(chunk(format))
+	buf.Write(info)
+	buf.Write(chunk(data))
+
+	return buf.Bytes()
+}
+
+type countingReaderAt struct {
+	r io.ReaderAt
+	n int64
+}
+
+func (c *countingReaderAt) ReadAt(b []byte, offset int64) (int, error) {
+	n, err := c.r.ReadAt(b, offset)
+	c.n += int64(n)
+
+	return n, err
+}
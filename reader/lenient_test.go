@@ -0,0 +1,256 @@
+package reader_test
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/internal"
+	"github.com/standoffvenus/goriffa/internal/test"
+	"github.com/standoffvenus/goriffa/reader"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLenientRIFFSizeZero(t *testing.T) {
+	chunks := []goriffa.Chunk{
+		{Identifier: goriffa.FourCCFormat, Data: []byte{1, 2}},
+		{Identifier: goriffa.FourCCData, Data: []byte{3, 4, 5}},
+	}
+
+	b := riffWithSize(0, chunk(chunks[0]), chunk(chunks[1]))
+	_, err := reader.New(bytes.NewReader(b))
+	assert.ErrorIs(t, err, goriffa.ErrCorrupted)
+
+	r, read := readLenient(t, b)
+	assert.Equal(t, chunks, read)
+	assert.Equal(t, []reader.WarningCode{reader.WarningRIFFSize}, codes(r))
+}
+
+func TestLenientRIFFSizeTooLarge(t *testing.T) {
+	c := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{1, 2}}
+
+	r, read := readLenient(t, riffWithSize(math.MaxUint32, chunk(c)))
+	assert.Equal(t, []goriffa.Chunk{c}, read)
+	assert.Equal(t, []reader.WarningCode{reader.WarningRIFFSize}, codes(r))
+}
+
+func TestLenientRIFFSizeTooSmall(t *testing.T) {
+	chunks := []goriffa.Chunk{
+		{Identifier: goriffa.FourCCFormat, Data: []byte{1, 2}},
+		{Identifier: goriffa.FourCCData, Data: []byte{3, 4}},
+	}
+
+	b := riffWithSize(4+10, chunk(chunks[0]), chunk(chunks[1]))
+	_, err := readAll(b)
+	assert.ErrorIs(t, err, goriffa.ErrCorrupted)
+
+	r, read := readLenient(t, b)
+	assert.Equal(t, chunks, read)
+	assert.Equal(t, []reader.WarningCode{reader.WarningRIFFSize}, codes(r))
+}
+
+func TestLenientMissingPad(t *testing.T) {
+	chunks := []goriffa.Chunk{
+		{Identifier: goriffa.FourCCFormat, Data: []byte{1, 2, 3}},
+		{Identifier: goriffa.FourCCData, Data: []byte{4, 5, 6}},
+	}
+
+	// Neither chunk is padded.
+	body := append(unpadded(chunks[0]), unpadded(chunks[1])...)
+	b := riffWithSize(uint32(4+len(body)), body)
+	_, err := readAll(b)
+	assert.ErrorIs(t, err, goriffa.ErrCorrupted)
+
+	r, read := readLenient(t, b)
+	assert.Equal(t, chunks, read)
+	assert.Equal(t, []reader.Warning{
+		{Code: reader.WarningMissingPad, Offset: 23, Message: "chunk is missing its padding byte"},
+		{Code: reader.WarningMissingPad, Offset: 34, Message: "chunk is missing its padding byte"},
+	}, r.Warnings())
+}
+
+func TestLenientResync(t *testing.T) {
+	chunks := []goriffa.Chunk{
+		{Identifier: goriffa.FourCCFormat, Data: []byte{1, 2}},
+		{Identifier: goriffa.FourCCData, Data: []byte{3, 4}},
+	}
+
+	garbage := []byte{0, 0xFF, 0, 0x01, 0x80, 0}
+	b := riffWithSize(0, chunk(chunks[0]), garbage, chunk(chunks[1]))
+
+	r, read := readLenient(t, b)
+	assert.Equal(t, chunks, read)
+	assert.Equal(t, []reader.WarningCode{reader.WarningRIFFSize, reader.WarningResync}, codes(r))
+	assert.Equal(t, int64(22), r.Warnings()[1].Offset)
+}
+
+func TestLenientTruncated(t *testing.T) {
+	format := goriffa.Chunk{Identifier: goriffa.FourCCFormat, Data: []byte{1, 2}}
+	data := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{3, 4, 5, 6}}
+
+	b := riffWithSize(4+10+12, chunk(format), chunk(data)[:10])
+
+	r, read := readLenient(t, b)
+	assert.Equal(t, []goriffa.Chunk{format, {Identifier: goriffa.FourCCData, Data: []byte{3, 4}}}, read)
+	assert.Equal(t, []reader.Warning{
+		{Code: reader.WarningTruncated, Offset: 22, Message: `chunk "data" truncated to 2 of 4 bytes`},
+	}, r.Warnings())
+}
+
+func TestLenientTruncatedStream(t *testing.T) {
+	data := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{3, 4, 5, 6}}
+
+	r, err := reader.New(bytes.NewReader(riffWithSize(4+12, chunk(data)[:10])), reader.Lenient())
+	assert.NoError(t, err)
+
+	_, cr, err := r.NextChunk()
+	assert.NoError(t, err)
+	streamed, err := io.ReadAll(cr)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{3, 4}, streamed)
+
+	_, err = r.NextHeader()
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, []reader.WarningCode{reader.WarningTruncated}, codes(r))
+}
+
+func TestLenientSkipChunk(t *testing.T) {
+	format := goriffa.Chunk{Identifier: goriffa.FourCCFormat, Data: []byte{1, 2, 3}}
+	data := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{4, 5, 6, 7}}
+
+	// The format chunk is not padded and the data chunk
+	// is truncated.
+	b := riffWithSize(4+11+12, unpadded(format), chunk(data)[:10])
+
+	r, err := reader.New(bytes.NewReader(b), reader.Lenient())
+	assert.NoError(t, err)
+
+	assert.NoError(t, r.SkipChunk())
+	header, err := r.NextHeader()
+	assert.NoError(t, err)
+	assert.Equal(t, goriffa.FourCCData, header.Identifier)
+	assert.NoError(t, r.SkipChunk())
+
+	_, err = r.NextHeader()
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, []reader.Warning{
+		{Code: reader.WarningMissingPad, Offset: 23, Message: "chunk is missing its padding byte"},
+		{Code: reader.WarningTruncated, Offset: 23, Message: `chunk "data" truncated to 2 of 4 bytes`},
+	}, r.Warnings())
+}
+
+func TestLenientOutOfBounds(t *testing.T) {
+	name := goriffa.Chunk{Identifier: fourCC("INAM"), Data: []byte("Song")}
+	info := list(internal.StringMust4Byte("INFO"), name)
+
+	// Claim the name is longer than its list.
+	copy(info[16:], internal.LittleEndianUInt32Bytes(10))
+	data := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{1, 2}}
+	b := riffWithSize(uint32(4+len(info)+10), info, chunk(data))
+
+	r, err := reader.New(bytes.NewReader(b), reader.Lenient())
+	assert.NoError(t, err)
+
+	l, err := r.ReadList()
+	assert.NoError(t, err)
+	chunks, err := l.ReadToEnd()
+	assert.NoError(t, err)
+	assert.Equal(t, []goriffa.Chunk{name}, chunks)
+
+	rest, err := r.ReadToEnd()
+	assert.NoError(t, err)
+	assert.Equal(t, []goriffa.Chunk{data}, rest)
+	assert.Equal(t, []reader.WarningCode{reader.WarningOutOfBounds}, codes(r))
+}
+
+func TestLenientTrailingData(t *testing.T) {
+	c := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{1, 2}}
+
+	r, read := readLenient(t, riffWithSize(4+10, chunk(c), []byte{1, 2, 3}))
+	assert.Equal(t, []goriffa.Chunk{c}, read)
+	assert.Equal(t, []reader.WarningCode{reader.WarningTrailingData, reader.WarningRIFFSize}, codes(r))
+}
+
+func TestLenientMissingPadAtEnd(t *testing.T) {
+	c := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{1, 2, 3}}
+
+	r, read := readLenient(t, riffWithSize(4+11, unpadded(c)))
+	assert.Equal(t, []goriffa.Chunk{c}, read)
+	assert.Equal(t, []reader.WarningCode{reader.WarningMissingPad}, codes(r))
+}
+
+func TestLenientIntactData(t *testing.T) {
+	for _, open := range []func() io.Reader{
+		func() io.Reader { r, _ := test.WAV(); return r },
+		func() io.Reader { r, _ := test.WEBP(); return r },
+	} {
+		strict, err := reader.New(open())
+		assert.NoError(t, err)
+		expected, err := strict.ReadToEnd()
+		assert.NoError(t, err)
+
+		lenient, err := reader.New(open(), reader.Lenient())
+		assert.NoError(t, err)
+		chunks, err := lenient.ReadToEnd()
+		assert.NoError(t, err)
+
+		assert.Equal(t, expected, chunks)
+		assert.Empty(t, lenient.Warnings())
+	}
+}
+
+func TestStrictHasNoWarnings(t *testing.T) {
+	c := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{1, 2}}
+
+	r, err := reader.New(bytes.NewReader(riffWithSize(4+10, chunk(c))))
+	assert.NoError(t, err)
+	_, err = r.ReadToEnd()
+	assert.NoError(t, err)
+	assert.Empty(t, r.Warnings())
+}
+
+func readLenient(t *testing.T, b []byte) (*reader.Reader, []goriffa.Chunk) {
+	r, err := reader.New(bytes.NewReader(b), reader.Lenient())
+	assert.NoError(t, err)
+
+	chunks, err := r.ReadToEnd()
+	assert.NoError(t, err)
+
+	return r, chunks
+}
+
+func readAll(b []byte) ([]goriffa.Chunk, error) {
+	r, err := reader.New(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	return r.ReadToEnd()
+}
+
+func codes(r *reader.Reader) []reader.WarningCode {
+	var codes []reader.WarningCode
+	for _, w := range r.Warnings() {
+		codes = append(codes, w.Code)
+	}
+
+	return codes
+}
+
+func riffWithSize(size uint32, content ...[]byte) []byte {
+	var buf bytes.Buffer
+	buf.Write(goriffa.FourCCRIFF[:])
+	buf.Write(internal.LittleEndianUInt32Bytes(size))
+	buf.Write(test.FileType[:])
+	for _, b := range content {
+		buf.Write(b)
+	}
+
+	return buf.Bytes()
+}
+
+func unpadded(c goriffa.Chunk) []byte {
+	return append(append(c.Identifier[:], internal.LittleEndianUInt32Bytes(uint32(len(c.Data)))...), c.Data...)
+}
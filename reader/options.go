@@ -0,0 +1,119 @@
+package reader
+
+import (
+	"fmt"
+
+	"github.com/standoffvenus/goriffa/internal"
+)
+
+// Option configures a Reader created by New (or an Index
+// created by NewIndex).
+//
+// The limit options below guard against untrusted RIFF
+// data: without them, ReadChunk holds whatever a chunk
+// declaring a 4 GB size contains in memory. A limit of 0
+// (the default) means no limit.
+type Option func(*Reader)
+
+// LimitError is returned when RIFF data exceeds one of
+// the limits set by MaxChunkSize, MaxChunks or MaxDepth.
+// LimitError wraps goriffa.ErrLimitExceeded.
+type LimitError struct {
+	// Limit names the limit exceeded, e.g.
+	// "chunk size".
+	Limit string
+
+	// Value holds the value that exceeded the
+	// limit, whereas Max holds the limit itself.
+	Value int64
+	Max   int64
+
+	// Offset holds the position of the offending
+	// chunk header within the RIFF data.
+	Offset int64
+}
+
+var _ interface{ Unwrap() error } = new(LimitError)
+
+// MaxChunkSize limits the size of any chunk read into
+// memory, i.e. by ReadChunk or Index.ReadChunk, to n bytes.
+// Chunks streamed via NextChunk, skipped or descended into
+// via ReadList may be of any size.
+//
+// ReadChunk also rejects a chunk claiming more data than
+// its RIFF data or list holds with goriffa.ErrCorrupted,
+// before reading any of it.
+func MaxChunkSize(n int64) Option {
+	return func(r *Reader) {
+		r.maxChunkSize = n
+	}
+}
+
+// MaxChunks limits the total number of chunks read -
+// including the sub-chunks of lists - to n.
+func MaxChunks(n int) Option {
+	return func(r *Reader) {
+		r.maxChunks = n
+	}
+}
+
+// MaxDepth limits how deeply lists may be nested to n;
+// a list that is not within another list has a depth
+// of 1.
+func MaxDepth(n int) Option {
+	return func(r *Reader) {
+		r.maxDepth = n
+	}
+}
+
+// SkipFiller makes the reader skip filler chunks (see
+// goriffa.IsFiller) transparently, as if they were not
+// part of the RIFF data at all. Skipped filler chunks
+// still count towards MaxChunks.
+func SkipFiller() Option {
+	return func(r *Reader) {
+		r.skipFiller = true
+	}
+}
+
+// Lenient makes the reader recover from damaged RIFF data
+// rather than failing, recording a Warning (see
+// Reader.Warnings) for every problem instead. A lenient
+// reader:
+//
+// - reads until the data ends, regardless of the RIFF
+// size (which may be 0 or 0xFFFFFFFF if the data was left
+// by a crashed recorder);
+//
+// - accepts chunks of odd size lacking a padding byte;
+//
+// - skips bytes that don't look like a chunk header until
+// it finds one that does, i.e. one with a printable FOURCC;
+//
+// - cuts chunks that outlast their list short;
+//
+// - returns whatever data a truncated final chunk holds,
+// after which io.EOF is returned.
+//
+// To look ahead, a lenient reader reads through a buffer
+// and never seeks. As the sizes of damaged data can't be
+// trusted, consider limiting them via MaxChunkSize. Lenient
+// has no effect on the chunks indexed by NewIndex.
+func Lenient() Option {
+	return func(r *Reader) {
+		r.lenient = true
+	}
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s: %s of %d exceeds maximum of %d (offset %d)",
+		internal.ErrLimitExceeded,
+		e.Limit,
+		e.Value,
+		e.Max,
+		e.Offset)
+}
+
+func (e *LimitError) Unwrap() error {
+	return internal.ErrLimitExceeded
+}
@@ -0,0 +1,250 @@
+package reader_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/internal"
+	"github.com/standoffvenus/goriffa/reader"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleMaxChunkSize() {
+	// A chunk claiming to hold nearly 4 GB of data.
+	var buf bytes.Buffer
+	buf.Write(header(int64(internal.LengthChunkHeader)))
+	buf.Write(goriffa.FourCCData[:])
+	buf.Write(internal.LittleEndianUInt32Bytes(math.MaxUint32 - 1))
+
+	r, err := reader.New(&buf, reader.MaxChunkSize(1<<20))
+	if err != nil {
+		panic(err)
+	}
+
+	var c goriffa.Chunk
+	_, err = r.ReadChunk(&c)
+	fmt.Println(errors.Is(err, goriffa.ErrLimitExceeded))
+	fmt.Println(err)
+	// Output:
+	// true
+	// limit exceeded: chunk size of 4294967294 exceeds maximum of 1048576 (offset 12)
+}
+
+func TestMaxChunkSize(t *testing.T) {
+	c := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{1, 2, 3, 4}}
+	b := append(header(c.ByteLength()), chunk(c)...)
+
+	r, err := reader.New(bytes.NewReader(b), reader.MaxChunkSize(4))
+	assert.NoError(t, err)
+	var read goriffa.Chunk
+	_, err = r.ReadChunk(&read)
+	assert.NoError(t, err)
+	assert.Equal(t, c, read)
+
+	r, err = reader.New(bytes.NewReader(b), reader.MaxChunkSize(3))
+	assert.NoError(t, err)
+	_, err = r.ReadChunk(&read)
+
+	var limitErr *reader.LimitError
+	assert.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, reader.LimitError{Limit: "chunk size", Value: 4, Max: 3, Offset: 12}, *limitErr)
+	assert.ErrorIs(t, err, goriffa.ErrLimitExceeded)
+}
+
+func TestMaxChunkSizeStreamed(t *testing.T) {
+	movi := list(internal.StringMust4Byte("movi"),
+		goriffa.Chunk{Identifier: fourCC("00dc"), Data: []byte{1, 2, 3, 4}})
+	c := goriffa.Chunk{Identifier: fourCC("abcd"), Data: []byte{5, 6, 7, 8, 9, 10}}
+	b := append(header(int64(len(movi))+c.ByteLength()), movi...)
+	b = append(b, chunk(c)...)
+
+	r, err := reader.New(bytes.NewReader(b), reader.MaxChunkSize(4))
+	assert.NoError(t, err)
+
+	l, err := r.ReadList()
+	assert.NoError(t, err)
+	var read goriffa.Chunk
+	_, err = l.ReadChunk(&read)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 3, 4}, read.Data)
+
+	h, data, err := r.NextChunk()
+	assert.NoError(t, err)
+	assert.Equal(t, c.Identifier, h.Identifier)
+	streamed, err := io.ReadAll(data)
+	assert.NoError(t, err)
+	assert.Equal(t, c.Data, streamed)
+
+	r, err = reader.New(bytes.NewReader(b), reader.MaxChunkSize(4))
+	assert.NoError(t, err)
+	assert.NoError(t, r.SkipChunk())
+	_, err = r.ReadChunk(&read)
+	assert.ErrorIs(t, err, goriffa.ErrLimitExceeded)
+
+	r, err = reader.New(bytes.NewReader(b), reader.MaxChunkSize(4))
+	assert.NoError(t, err)
+	_, err = r.ReadChunk(&read)
+	assert.ErrorIs(t, err, goriffa.ErrLimitExceeded)
+}
+
+func TestMaxChunkSizeOutlastsRIFF(t *testing.T) {
+	var buf bytes.Buffer
+	buf.Write(header(int64(internal.LengthChunkHeader) + 4))
+	buf.Write(goriffa.FourCCData[:])
+	buf.Write(internal.LittleEndianUInt32Bytes(1 << 10))
+	buf.Write([]byte{1, 2, 3, 4})
+	b := buf.Bytes()
+
+	r, err := reader.New(bytes.NewReader(b), reader.MaxChunkSize(1<<20))
+	assert.NoError(t, err)
+	n, err := r.ReadChunk(new(goriffa.Chunk))
+	assert.ErrorIs(t, err, goriffa.ErrCorrupted)
+	assert.EqualError(t, err, `corrupted: chunk "data" at offset 12 outlasts its RIFF by 1020 bytes`)
+	assert.Equal(t, internal.LengthChunkHeader, n)
+
+	// Without a limit, the data is read until it ends.
+	r, err = reader.New(bytes.NewReader(b))
+	assert.NoError(t, err)
+	n, err = r.ReadChunk(new(goriffa.Chunk))
+	assert.ErrorIs(t, err, goriffa.ErrCorrupted)
+	assert.Equal(t, internal.LengthChunkHeader+4, n)
+}
+
+func TestMaxChunks(t *testing.T) {
+	chunks := []goriffa.Chunk{
+		{Identifier: fourCC("abcd"), Data: []byte{1}},
+		{Identifier: fourCC("efgh"), Data: []byte{2}},
+		{Identifier: fourCC("ijkl"), Data: []byte{3}},
+	}
+
+	var buf bytes.Buffer
+	buf.Write(header(3 * chunks[0].ByteLength()))
+	for _, c := range chunks {
+		buf.Write(chunk(c))
+	}
+
+	r, err := reader.New(&buf, reader.MaxChunks(2))
+	assert.NoError(t, err)
+
+	assert.NoError(t, r.SkipChunk())
+	assert.NoError(t, r.SkipChunk())
+	_, err = r.ReadChunk(new(goriffa.Chunk))
+
+	var limitErr *reader.LimitError
+	assert.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, reader.LimitError{Limit: "chunk count", Value: 3, Max: 2, Offset: 32}, *limitErr)
+}
+
+func TestMaxChunksCountsSubChunks(t *testing.T) {
+	info := list(internal.StringMust4Byte("INFO"),
+		goriffa.Chunk{Identifier: fourCC("INAM"), Data: []byte("Song")})
+	b := append(header(int64(len(info))), info...)
+
+	r, err := reader.New(bytes.NewReader(b), reader.MaxChunks(1))
+	assert.NoError(t, err)
+
+	l, err := r.ReadList()
+	assert.NoError(t, err)
+	_, err = l.ReadChunk(new(goriffa.Chunk))
+	assert.ErrorIs(t, err, goriffa.ErrLimitExceeded)
+}
+
+func TestMaxDepth(t *testing.T) {
+	inner := list(internal.StringMust4Byte("in  "),
+		goriffa.Chunk{Identifier: fourCC("abcd"), Data: []byte{1}})
+	outer := list(internal.StringMust4Byte("out "),
+		goriffa.Chunk{Identifier: goriffa.FourCCList, Data: inner[internal.LengthChunkHeader:]})
+	b := append(header(int64(len(outer))), outer...)
+
+	r, err := reader.New(bytes.NewReader(b), reader.MaxDepth(2))
+	assert.NoError(t, err)
+	l, err := r.ReadList()
+	assert.NoError(t, err)
+	_, err = l.ReadList()
+	assert.NoError(t, err)
+
+	r, err = reader.New(bytes.NewReader(b), reader.MaxDepth(1))
+	assert.NoError(t, err)
+	l, err = r.ReadList()
+	assert.NoError(t, err)
+	_, err = l.ReadList()
+
+	var limitErr *reader.LimitError
+	assert.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, reader.LimitError{Limit: "list depth", Value: 2, Max: 1, Offset: 24}, *limitErr)
+}
+
+func TestNewIndexLimits(t *testing.T) {
+	_, err := reader.NewIndex(bytes.NewReader(indexedRIFF()), reader.MaxChunks(5), reader.MaxChunkSize(30))
+	assert.NoError(t, err)
+
+	_, err = reader.NewIndex(bytes.NewReader(indexedRIFF()), reader.MaxChunks(3))
+	var limitErr *reader.LimitError
+	assert.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, reader.LimitError{Limit: "chunk count", Value: 4, Max: 3, Offset: 48}, *limitErr)
+
+	idx, err := reader.NewIndex(bytes.NewReader(indexedRIFF()), reader.MaxChunkSize(4))
+	assert.NoError(t, err)
+	entries := idx.Entries()
+	_, err = idx.ReadChunk(entries[len(entries)-1])
+	assert.NoError(t, err)
+	_, err = idx.ReadChunk(entries[1])
+	assert.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, "chunk size", limitErr.Limit)
+	assert.Equal(t, int64(24), limitErr.Offset)
+
+	_, err = reader.NewIndex(bytes.NewReader(indexedRIFF()), reader.MaxDepth(0))
+	assert.NoError(t, err)
+}
+
+func TestSkipFiller(t *testing.T) {
+	format := goriffa.Chunk{Identifier: goriffa.FourCCFormat, Data: []byte{1, 2, 3}}
+	name := goriffa.Chunk{Identifier: fourCC("INAM"), Data: []byte("Song")}
+	data := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{4, 5}}
+	info := list(internal.StringMust4Byte("INFO"),
+		goriffa.Chunk{Identifier: goriffa.FourCCPad, Data: make([]byte, 6)},
+		name)
+
+	var buf bytes.Buffer
+	body := bytes.Join([][]byte{
+		chunk(goriffa.Chunk{Identifier: goriffa.FourCCJunk, Data: make([]byte, 28)}),
+		chunk(format),
+		info,
+		chunk(goriffa.Chunk{Identifier: goriffa.FourCCFiller, Data: make([]byte, 4)}),
+		chunk(data),
+	}, nil)
+	buf.Write(header(int64(len(body))))
+	buf.Write(body)
+
+	r, err := reader.New(bytes.NewReader(buf.Bytes()), reader.SkipFiller())
+	assert.NoError(t, err)
+
+	var read goriffa.Chunk
+	_, err = r.ReadChunk(&read)
+	assert.NoError(t, err)
+	assert.Equal(t, format, read)
+
+	l, err := r.ReadList()
+	assert.NoError(t, err)
+	chunks, err := l.ReadToEnd()
+	assert.NoError(t, err)
+	assert.Equal(t, []goriffa.Chunk{name}, chunks)
+
+	h, err := r.NextHeader()
+	assert.NoError(t, err)
+	assert.Equal(t, goriffa.FourCCData, h.Identifier)
+
+	idx, err := reader.NewIndex(bytes.NewReader(buf.Bytes()), reader.SkipFiller())
+	assert.NoError(t, err)
+
+	var identifiers []internal.FourCC
+	for _, e := range idx.Entries() {
+		identifiers = append(identifiers, e.Identifier)
+	}
+	assert.Equal(t, []internal.FourCC{goriffa.FourCCFormat, goriffa.FourCCList, name.Identifier, goriffa.FourCCData}, identifiers)
+}
@@ -0,0 +1,322 @@
+package reader
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/internal"
+)
+
+// ErrInvalidPath is returned when a chunk path cannot be
+// parsed (see ParsePath).
+var ErrInvalidPath error = errors.New("invalid chunk path")
+
+// Path selects chunks by their position within the chunk
+// tree; see ParsePath. The zero value selects no chunks.
+type Path struct {
+	elements []pathElement
+}
+
+// pathElement selects chunks among the chunks of a list.
+type pathElement struct {
+	identifier internal.FourCC
+
+	// listType holds the list type lists must have, if
+	// hasListType is set.
+	listType    internal.FileType
+	hasListType bool
+
+	// index holds the position of the chunk to select
+	// among the chunks matching, or -1 to select all.
+	index int
+}
+
+// Match describes a chunk found by Reader.Find or
+// Reader.FindAll.
+type Match struct {
+	internal.Chunk
+
+	// Offset holds the position of the chunk's header
+	// within the RIFF data.
+	Offset int64
+}
+
+// ParsePath will parse the provided chunk path. A path
+// is made up of elements separated by "/", each selecting
+// chunks among the chunks of the list selected by the
+// previous element (or the top-level chunks, for the first
+// element). An element is made up of:
+//
+// - The chunk identifier. Identifiers shorter than four
+// characters are padded with spaces, so "fmt" selects
+// "fmt " chunks.
+//
+// - For lists, optionally, the list type between brackets.
+//
+// - Optionally, "#" followed by the position of the chunk
+// to select among the chunks matching the element,
+// starting at 0. Otherwise, every chunk matching the
+// element is selected.
+//
+// For example, "LIST[INFO]/INAM" selects the "INAM" chunks
+// of every "INFO" list, and "LIST[hdrl]/LIST[strl]#1/strf"
+// selects the "strf" chunks of the second "strl" list of
+// every "hdrl" list. The paths passed to a WalkFunc are
+// valid paths as well, unless a FOURCC holds any of the
+// characters "/", "[", "]" or "#".
+//
+// If the path cannot be parsed, an error wrapping
+// ErrInvalidPath is returned.
+func ParsePath(path string) (Path, error) {
+	if path == "" {
+		return Path{}, fmt.Errorf("%w: empty path", ErrInvalidPath)
+	}
+
+	var p Path
+	for _, s := range strings.Split(path, "/") {
+		e, err := parsePathElement(s)
+		if err != nil {
+			return Path{}, fmt.Errorf("%w: %q: %s", ErrInvalidPath, path, err)
+		}
+		p.elements = append(p.elements, e)
+	}
+
+	return p, nil
+}
+
+// MustParsePath is like ParsePath, but panics if the path
+// cannot be parsed.
+func MustParsePath(path string) Path {
+	p, err := ParsePath(path)
+	if err != nil {
+		internal.Panic(err)
+	}
+
+	return p
+}
+
+// String returns the path as it would be parsed by
+// ParsePath.
+func (p Path) String() string {
+	elements := make([]string, len(p.elements))
+	for i, e := range p.elements {
+		s := e.identifier.String()
+		if e.hasListType {
+			s += "[" + e.listType.String() + "]"
+		}
+		if e.index >= 0 {
+			s += "#" + strconv.Itoa(e.index)
+		}
+		elements[i] = s
+	}
+
+	return strings.Join(elements, "/")
+}
+
+// Find will read the remaining chunks of the Reader until
+// the first chunk selected by the provided path, which is
+// returned along with its data. If there is no such chunk,
+// false is returned.
+//
+// The Reader may be read further once Find returns, but
+// if the chunk found is within a list, the rest of the
+// list is skipped: calling Find again may thus miss
+// chunks the path selects. Use FindAll (or Index.FindAll)
+// to find every such chunk.
+func (r *Reader) Find(p Path) (Match, bool, error) {
+	var matches []Match
+	if err := r.find(p.elements, &matches, 1); err != nil {
+		return Match{}, false, err
+	}
+	if len(matches) == 0 {
+		return Match{}, false, nil
+	}
+
+	return matches[0], true, nil
+}
+
+// FindAll will read the remaining chunks of the Reader,
+// returning every chunk selected by the provided path
+// along with its data. If an error occurs, the chunks
+// found until then are returned along with the error.
+func (r *Reader) FindAll(p Path) ([]Match, error) {
+	var matches []Match
+	err := r.find(p.elements, &matches, -1)
+
+	return matches, err
+}
+
+// Find returns the first chunk indexed that the provided
+// path selects. If there is no such chunk, false is
+// returned.
+func (idx *Index) Find(p Path) (Entry, bool) {
+	entries := idx.find(p.elements, -1, 1)
+	if len(entries) == 0 {
+		return Entry{}, false
+	}
+
+	return entries[0], true
+}
+
+// FindAll returns every chunk indexed that the provided
+// path selects, in the order they appear within the RIFF
+// data.
+func (idx *Index) FindAll(p Path) []Entry {
+	return idx.find(p.elements, -1, -1)
+}
+
+// find will append the chunks selected by the elements to
+// matches, until there are limit matches (unless limit is
+// negative).
+func (r *Reader) find(elements []pathElement, matches *[]Match, limit int) error {
+	if len(elements) == 0 {
+		return nil
+	}
+
+	e, last := elements[0], len(elements) == 1
+	for count := 0; limit < 0 || len(*matches) < limit; {
+		header, err := r.NextHeader()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if header.Identifier != e.identifier || !last && !goriffa.IsList(header.Identifier) {
+			if err := r.SkipChunk(); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		offset := r.Offset() - int64(internal.LengthChunkHeader)
+		if last {
+			var c internal.Chunk
+			if _, err := r.ReadChunk(&c); err != nil {
+				return err
+			}
+			if !e.matchesListType(c.Data) {
+				continue
+			}
+
+			if e.index < 0 || count == e.index {
+				*matches = append(*matches, Match{Chunk: c, Offset: offset})
+			}
+		} else {
+			list, err := r.ReadList()
+			if err != nil {
+				return err
+			}
+			if !e.matchesListType(list.fileType[:]) {
+				continue
+			}
+
+			if e.index < 0 || count == e.index {
+				if err := list.find(elements[1:], matches, limit); err != nil {
+					return err
+				}
+			}
+		}
+
+		// No later chunk can match.
+		if count == e.index {
+			return nil
+		}
+		count++
+	}
+
+	return nil
+}
+
+func (idx *Index) find(elements []pathElement, parent, limit int) []Entry {
+	if len(elements) == 0 {
+		return nil
+	}
+
+	var entries []Entry
+	e, count := elements[0], 0
+	for i, entry := range idx.entries {
+		if entry.Parent != parent || entry.Identifier != e.identifier {
+			continue
+		}
+		if e.hasListType && (!goriffa.IsList(entry.Identifier) || entry.ListType != e.listType) {
+			continue
+		}
+
+		if e.index < 0 || count == e.index {
+			if len(elements) == 1 {
+				entries = append(entries, entry)
+			} else {
+				entries = append(entries, idx.find(elements[1:], i, limit-len(entries))...)
+			}
+		}
+		if limit >= 0 && len(entries) >= limit {
+			return entries[:limit]
+		}
+		if count == e.index {
+			break
+		}
+		count++
+	}
+
+	return entries
+}
+
+// matchesListType reports whether the list type held by
+// the first four bytes of b matches the element's.
+func (e pathElement) matchesListType(b []byte) bool {
+	if !e.hasListType {
+		return true
+	}
+
+	return len(b) >= len(e.listType) && string(b[:len(e.listType)]) == string(e.listType[:])
+}
+
+func parsePathElement(s string) (pathElement, error) {
+	e := pathElement{index: -1}
+
+	if i := strings.LastIndexByte(s, '#'); i >= 0 {
+		index, err := strconv.Atoi(s[i+1:])
+		if err != nil || index < 0 || strings.HasPrefix(s[i+1:], "+") {
+			return e, fmt.Errorf("invalid index %q", s[i+1:])
+		}
+		e.index, s = index, s[:i]
+	}
+
+	if i := strings.IndexByte(s, '['); i >= 0 {
+		if !strings.HasSuffix(s, "]") {
+			return e, fmt.Errorf("unterminated list type in %q", s)
+		}
+
+		listType, err := parsePathFourCC(s[i+1 : len(s)-1])
+		if err != nil {
+			return e, err
+		}
+		e.listType, e.hasListType, s = internal.FileType(listType), true, s[:i]
+	}
+
+	identifier, err := parsePathFourCC(s)
+	if err != nil {
+		return e, err
+	}
+	if e.hasListType && !goriffa.IsList(identifier) {
+		return e, fmt.Errorf("%q is not a list", identifier)
+	}
+	e.identifier = identifier
+
+	return e, nil
+}
+
+// parsePathFourCC parses a FOURCC of a path, which may
+// not hold the characters separating its elements.
+func parsePathFourCC(s string) (internal.FourCC, error) {
+	if strings.ContainsAny(s, "[]#/") {
+		return internal.FourCC{}, fmt.Errorf("invalid FOURCC %q", s)
+	}
+
+	return internal.ParseFourCC(s)
+}
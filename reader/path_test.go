@@ -0,0 +1,187 @@
+package reader_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/internal"
+	"github.com/standoffvenus/goriffa/reader"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleReader_Find() {
+	r, err := reader.New(bytes.NewReader(pathRIFF()))
+	if err != nil {
+		panic(err)
+	}
+
+	m, found, err := r.Find(reader.MustParsePath("LIST[hdrl]/LIST[strl]#1/strf"))
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(found, m.Offset, string(m.Data))
+	// Output: true 98 video
+}
+
+func TestParsePath(t *testing.T) {
+	for _, path := range []string{
+		"INAM",
+		"fmt ",
+		"LIST[INFO]/INAM",
+		"LIST[hdrl]/LIST[strl]#1/strf",
+		"LIST#0",
+		"RIFF[AVIX]/LIST[movi]/00dc#12",
+	} {
+		p, err := reader.ParsePath(path)
+		assert.NoError(t, err, path)
+		assert.Equal(t, path, p.String())
+	}
+
+	// Short FOURCCs are padded with spaces.
+	p, err := reader.ParsePath("LIST[sub]/fmt")
+	assert.NoError(t, err)
+	assert.Equal(t, "LIST[sub ]/fmt ", p.String())
+}
+
+func TestParsePathInvalid(t *testing.T) {
+	for _, path := range []string{
+		"",
+		"/",
+		"LIST/",
+		"/INAM",
+		"TOOLONG",
+		"LIST[INFO",
+		"LIST[]",
+		"LIST[LONGER]",
+		"INAM[INFO]",
+		"INAM#",
+		"INAM#-1",
+		"INAM#+1",
+		"INAM#one",
+		"#1",
+	} {
+		_, err := reader.ParsePath(path)
+		assert.ErrorIs(t, err, reader.ErrInvalidPath, path)
+	}
+
+	assert.Panics(t, func() { reader.MustParsePath("LIST[") })
+}
+
+func TestFind(t *testing.T) {
+	for path, expected := range map[string][]reader.Match{
+		"fmt": {
+			{Chunk: goriffa.Chunk{Identifier: goriffa.FourCCFormat, Data: []byte{1, 2, 3}}, Offset: 12},
+		},
+		"LIST[INFO]/INAM": {
+			{Chunk: goriffa.Chunk{Identifier: fourCC("INAM"), Data: []byte("Song")}, Offset: 124},
+		},
+		"LIST[hdrl]/LIST[strl]/strf": {
+			{Chunk: goriffa.Chunk{Identifier: fourCC("strf"), Data: []byte("audio")}, Offset: 60},
+			{Chunk: goriffa.Chunk{Identifier: fourCC("strf"), Data: []byte("video")}, Offset: 98},
+		},
+		"LIST[hdrl]/LIST[strl]#1/strf": {
+			{Chunk: goriffa.Chunk{Identifier: fourCC("strf"), Data: []byte("video")}, Offset: 98},
+		},
+		"LIST[hdrl]/LIST#1/strh": {
+			{Chunk: goriffa.Chunk{Identifier: fourCC("strh"), Data: []byte("vids")}, Offset: 86},
+		},
+		"LIST/ICMT#1": {
+			{Chunk: goriffa.Chunk{Identifier: fourCC("ICMT"), Data: []byte("two")}, Offset: 148},
+		},
+		"LIST[INFO]": {
+			{Chunk: goriffa.Chunk{Identifier: goriffa.FourCCList, Data: pathRIFF()[120:160]}, Offset: 112},
+		},
+		"LIST[hdrl]/LIST[strl]#2/strf": nil,
+		"LIST[INFO]/strf":              nil,
+		"LIST[movi]":                   nil,
+		"INAM":                         nil,
+	} {
+		p := reader.MustParsePath(path)
+
+		r, err := reader.New(bytes.NewReader(pathRIFF()))
+		assert.NoError(t, err)
+		matches, err := r.FindAll(p)
+		assert.NoError(t, err, path)
+		assert.Equal(t, expected, matches, path)
+
+		r, err = reader.New(bytes.NewReader(pathRIFF()))
+		assert.NoError(t, err)
+		m, found, err := r.Find(p)
+		assert.NoError(t, err, path)
+		assert.Equal(t, len(expected) > 0, found, path)
+		if found {
+			assert.Equal(t, expected[0], m, path)
+		}
+
+		idx, err := reader.NewIndex(bytes.NewReader(pathRIFF()))
+		assert.NoError(t, err)
+		entries := idx.FindAll(p)
+		assert.Equal(t, len(expected), len(entries), path)
+		for i, e := range entries {
+			assert.Equal(t, expected[i].Offset, e.Offset, path)
+			c, err := idx.ReadChunk(e)
+			assert.NoError(t, err, path)
+			assert.Equal(t, expected[i].Chunk, c, path)
+		}
+
+		e, found := idx.Find(p)
+		assert.Equal(t, len(expected) > 0, found, path)
+		if found {
+			assert.Equal(t, expected[0].Offset, e.Offset, path)
+		}
+	}
+}
+
+func TestFindNext(t *testing.T) {
+	r, err := reader.New(bytes.NewReader(pathRIFF()))
+	assert.NoError(t, err)
+
+	p := reader.MustParsePath("LIST[hdrl]/LIST[strl]/strh")
+	m, found, err := r.Find(p)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("auds"), m.Data)
+
+	// The rest of the "hdrl" list was skipped.
+	m, found, err = r.Find(reader.MustParsePath("LIST[INFO]/ICMT"))
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("one"), m.Data)
+}
+
+func TestFindCorrupted(t *testing.T) {
+	data := pathRIFF()
+
+	r, err := reader.New(bytes.NewReader(data[:len(data)-3]))
+	assert.NoError(t, err)
+	matches, err := r.FindAll(reader.MustParsePath("LIST/ICMT"))
+	assert.ErrorIs(t, err, goriffa.ErrCorrupted)
+	assert.Len(t, matches, 1)
+}
+
+func pathRIFF() []byte {
+	strl := func(header, format string) goriffa.Chunk {
+		l := list(internal.StringMust4Byte("strl"),
+			goriffa.Chunk{Identifier: fourCC("strh"), Data: []byte(header)},
+			goriffa.Chunk{Identifier: fourCC("strf"), Data: []byte(format)})
+
+		return goriffa.Chunk{Identifier: goriffa.FourCCList, Data: l[internal.LengthChunkHeader:]}
+	}
+
+	var buf bytes.Buffer
+	for _, b := range [][]byte{
+		chunk(goriffa.Chunk{Identifier: goriffa.FourCCFormat, Data: []byte{1, 2, 3}}),
+		list(internal.StringMust4Byte("hdrl"), strl("auds", "audio"), strl("vids", "video")),
+		list(internal.StringMust4Byte("INFO"),
+			goriffa.Chunk{Identifier: fourCC("INAM"), Data: []byte("Song")},
+			goriffa.Chunk{Identifier: fourCC("ICMT"), Data: []byte("one")},
+			goriffa.Chunk{Identifier: fourCC("ICMT"), Data: []byte("two")}),
+	} {
+		buf.Write(b)
+	}
+
+	return append(header(int64(buf.Len())), buf.Bytes()...)
+}
@@ -3,11 +3,12 @@
 package reader
 
 import (
-	"bytes"
+	"bufio"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"math"
 
 	"github.com/standoffvenus/goriffa"
 	"github.com/standoffvenus/goriffa/internal"
@@ -16,15 +17,65 @@ import (
 // Reader provides a mechanism for reading RIFF
 // data chunks.
 type Reader struct {
-	fileType  internal.FileType
-	size      uint32
-	bytesRead int64
+	identifier internal.FourCC
+	fileType   internal.FileType
+	size       int64
+	bytesRead  int64
 
-	r io.Reader
+	// start holds the position within the RIFF data at
+	// which bytesRead began counting, and depth holds
+	// how deeply the list read is nested.
+	start int64
+	depth int
+
+	// byteOrder and ds64 are only set on the top-level
+	// Reader: the byte order of the size fields and
+	// the 64-bit sizes of RF64 data respectively.
+	byteOrder binary.ByteOrder
+	ds64      *internal.DS64
+
+	// Limits set via Option and the number of chunks read,
+	// which are only set on the top-level Reader.
+	maxChunkSize int64
+	maxChunks    int
+	maxDepth     int
+	chunks       int
+	skipFiller   bool
+
+	// lenient, warnings, overrun and truncated are only
+	// set on the top-level Reader, see Lenient.
+	lenient   bool
+	warnings  []Warning
+	overrun   bool
+	truncated bool
+
+	// form is only set on the top-level Reader of a form
+	// read by Forms, see bounded.
+	form bool
+
+	// next holds the value bytesRead will have once
+	// the most recent chunk has been fully consumed,
+	// i.e. where the next chunk header begins. padded
+	// is set if next includes a padding byte.
+	next   int64
+	padded bool
+
+	// peeked holds the header read by NextHeader, which
+	// has yet to be consumed.
+	peeked *internal.ChunkHeader
+
+	// A Reader for a list reads through its parent,
+	// whereas the top-level Reader reads from r.
+	parent *Reader
+	r      io.Reader
 }
 
 var _ goriffa.Reader = new(Reader)
 
+// maxPrealloc is the most memory allocated up front to hold
+// chunk data, see readData.
+const maxPrealloc = 1 << 20
+
 var (
 	errCorruptedTooShort        error = fmt.Errorf("%w: %s", internal.ErrCorrupted, internal.ErrBufferUnderflow)
 	errCorruptedReadOutOfBounds error = fmt.Errorf("%w: read outside file size - file must be corrupt", internal.ErrCorrupted)
@@ -35,8 +86,19 @@ var (
 // reader does not begin with a valid RIFF header,
 // goriffa.ErrCorrupted will be returned.
 //
+// Big-endian "RIFX" data is detected and read transparently;
+// see ByteOrder.
+//
+// Besides "RIFF", RF64 and BW64 headers are accepted;
+// their "ds64" chunk is read by New, after which the
+// 64-bit sizes it holds are used in place of any size
+// field of 0xFFFFFFFF.
+//
+// The reader may be configured with any number of
+// options, such as MaxChunkSize.
+//
 // The returned reader is NOT concurrent-safe.
-func New(r io.Reader) (*Reader, error) {
+func New(r io.Reader, options ...Option) (*Reader, error) {
 	var (
 		riffPrefix internal.FourCC
 		size       [4]byte
@@ -50,20 +112,54 @@ func New(r io.Reader) (*Reader, error) {
 	); err != nil {
 		return nil, wrap(err)
 	}
-	if !bytes.Equal(riffPrefix[:], goriffa.FourCCRIFF[:]) {
+
+	return newReader(r, riffPrefix, size, fileType, 0, options)
+}
+
+// newReader will create a new RIFF reader of the RIFF data
+// read from r, beginning at offset within r, whose header
+// has been read already.
+func newReader(r io.Reader, riffPrefix internal.FourCC, size [4]byte, fileType internal.FileType, offset int64, options []Option) (*Reader, error) {
+	if !isRIFFPrefix(riffPrefix) {
 		return nil, fmt.Errorf("%w: data does not begin with RIFF header", internal.ErrCorrupted)
 	}
 
-	parsedSize := binary.LittleEndian.Uint32(size[:])
+	var byteOrder binary.ByteOrder = binary.LittleEndian
+	if riffPrefix == goriffa.FourCCRIFX {
+		byteOrder = binary.BigEndian
+	}
+
+	parsedSize := byteOrder.Uint32(size[:])
 	riffReader := &Reader{
-		fileType: fileType,
-		size:     parsedSize,
-		r:        r,
+		identifier: riffPrefix,
+		fileType:   fileType,
+		size:       int64(parsedSize),
+		start:      offset + int64(len(riffPrefix)+len(size)),
+		byteOrder:  byteOrder,
+		r:          r,
+	}
+	riffReader.bytesRead = int64(len(fileType))
+	riffReader.next = riffReader.bytesRead
+	for _, option := range options {
+		option(riffReader)
+	}
+	if _, buffered := r.(*bufio.Reader); riffReader.lenient && !buffered {
+		// Recovering requires looking ahead.
+		riffReader.r = bufio.NewReader(r)
+	}
+
+	if riffPrefix == goriffa.FourCCRF64 || riffPrefix == goriffa.FourCCBW64 {
+		if err := riffReader.readDS64(); err != nil {
+			return nil, err
+		}
 	}
 	if riffReader.size < 4 {
-		return nil, fmt.Errorf("%w: impossibly small file size (%d)", internal.ErrCorrupted, riffReader.size)
+		if !riffReader.lenient {
+			return nil, fmt.Errorf("%w: impossibly small file size (%d)", internal.ErrCorrupted, riffReader.size)
+		}
+		riffReader.warn(WarningRIFFSize, riffReader.sizeOffset(), "impossibly small RIFF size (%d)", riffReader.size)
+		riffReader.overrun = true
 	}
-	riffReader.bytesRead = int64(len(fileType))
 
 	return riffReader, nil
 }
@@ -75,36 +171,197 @@ func New(r io.Reader) (*Reader, error) {
 // The returned number of bytes will be:
 //  (goriffa.LengthChunkHeader + len(c.Data)) + 1 (if there is padding)
 //
+// Lists are returned like any other chunk, i.e. with the
+// list type and all sub-chunks held in the chunk's data;
+// use ReadList to descend into a list instead.
+//
 // If at any point during reads an underflow occurs, ErrCorrupted
 // will be returned. If any underlying reader error occurs,
 // it will be returned.
 func (r *Reader) ReadChunk(chunk *internal.Chunk) (int, error) {
-	var header [internal.LengthChunkHeader]byte
-	headerN, headerErr := r.read(header[:])
+	header, headerN, headerErr := r.readHeader()
 	if headerErr != nil {
 		return headerN, headerErr
-	} else if headerN < len(header) {
-		return headerN, errCorruptedTooShort
 	}
 
-	chunkSize := binary.LittleEndian.Uint32(header[4:])
-	data := internal.Pad(make([]byte, chunkSize))
-	dataN, dataErr := r.read(data)
+	root := r.root()
+	offset := r.offset() - int64(headerN)
+	if err := root.checkSize(header, offset); err != nil {
+		return headerN, err
+	}
+	if remaining := r.size - r.bytesRead; root.maxChunkSize > 0 && !root.lenient && header.Size > remaining {
+		return headerN, fmt.Errorf("%w: chunk %q at offset %d outlasts its %s by %d bytes", internal.ErrCorrupted, header.Identifier, offset, r.identifier, header.Size-remaining)
+	}
+
+	size := internal.PaddedLength(header.Size)
+	if root.lenient {
+		// The padding byte may be missing, so leave it to be
+		// skipped along with the next header.
+		size = header.Size
+	}
+	data, dataErr := readData(r.readFull, size)
+	dataN := len(data)
 
 	totalN := headerN + dataN
 
-	chunk.Identifier = internal.FourCC(internal.Must4Byte(header[:4]))
-	chunk.Data = data[:chunkSize] // Padded chunks may contain an extra byte
+	chunk.Identifier = header.Identifier
+	chunk.Data = data
+	if int64(dataN) > header.Size {
+		chunk.Data = data[:header.Size] // Padded chunks may contain an extra byte
+	}
+
+	if root.lenient && int64(dataN) < size && isShort(dataErr) {
+		// Keep whatever data a truncated chunk holds.
+		r.truncate(header, int64(dataN))
+
+		return totalN, nil
+	}
 
 	if dataErr != nil {
 		return totalN, dataErr
-	} else if dataN < len(data) {
+	} else if int64(dataN) < size {
 		return totalN, errCorruptedTooShort
 	}
 
 	return totalN, nil
 }
 
+// NextChunk will read the header of the next chunk and
+// return it along with a reader limited to the chunk's
+// data, allowing the data to be streamed rather than held
+// in memory entirely. Once the data has been read in full,
+// the padding byte (if any) is skipped as well.
+//
+// The returned reader reads through this Reader, so it is
+// only valid until this Reader is read again; any unread
+// data is then skipped and the returned reader will return
+// goriffa.ErrClosed. If the data turns out to be shorter
+// than the chunk size, goriffa.ErrCorrupted is returned
+// from the data reader.
+//
+// If there are no more chunks, io.EOF is returned.
+func (r *Reader) NextChunk() (internal.ChunkHeader, io.Reader, error) {
+	header, _, err := r.readHeader()
+	if err != nil {
+		return header, nil, err
+	}
+
+	return header, &chunkReader{
+		r:         r,
+		end:       r.next,
+		header:    header,
+		remaining: header.Size,
+	}, nil
+}
+
+// ReadList will read the header of the next chunk, which is
+// expected to be a list (see goriffa.IsList), and return a
+// Reader over the list's sub-chunks. The returned Reader's
+// FileType is the list type (e.g. "INFO") and its Size is
+// the size of the list chunk. It follows the same semantics
+// as this Reader - including padding and bounds checks -
+// and returns io.EOF once the end of the list is reached.
+//
+// The list Reader reads through this Reader, so both may
+// not be used in tandem: once this Reader is read again,
+// any unread part of the list is skipped and the list
+// Reader must no longer be used. Lists may be nested to
+// any depth.
+//
+// If the next chunk is not a list, its header is consumed
+// and goriffa.ErrBadChunk is returned.
+func (r *Reader) ReadList() (*Reader, error) {
+	header, _, err := r.readHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	if !goriffa.IsList(header.Identifier) {
+		return nil, fmt.Errorf("%w: %q is not a list", internal.ErrBadChunk, header.Identifier)
+	}
+	if header.Size < 4 {
+		return nil, fmt.Errorf("%w: impossibly small list size (%d)", internal.ErrCorrupted, header.Size)
+	}
+	if err := r.root().checkDepth(r.depth+1, r.offset()-int64(internal.LengthChunkHeader)); err != nil {
+		return nil, err
+	}
+
+	start := r.offset()
+
+	var listType internal.FileType
+	if n, err := r.readFull(listType[:]); err != nil {
+		return nil, wrap(err)
+	} else if n < len(listType) {
+		return nil, errCorruptedTooShort
+	}
+
+	return &Reader{
+		identifier: header.Identifier,
+		fileType:   listType,
+		size:       header.Size,
+		bytesRead:  int64(len(listType)),
+		next:       int64(len(listType)),
+		start:      start,
+		depth:      r.depth + 1,
+		parent:     r,
+	}, nil
+}
+
+// NextHeader will read the header of the next chunk without
+// reading its data, skipping whatever remains of the previous
+// chunk. The header is then consumed by the next call to
+// ReadChunk, NextChunk, ReadList or SkipChunk, which act on
+// the chunk as if its header had not been read yet; calling
+// NextHeader again returns the same header.
+//
+// If there are no more chunks, io.EOF is returned.
+func (r *Reader) NextHeader() (internal.ChunkHeader, error) {
+	if r.peeked != nil {
+		return *r.peeked, nil
+	}
+
+	header, _, err := r.readHeader()
+	if err != nil {
+		return header, err
+	}
+	r.peeked = &header
+
+	return header, nil
+}
+
+// SkipChunk will skip the next chunk (i.e. the chunk whose
+// header was returned by NextHeader, if any) without reading
+// its data into memory. If the underlying reader implements
+// io.Seeker, the data is skipped by seeking; otherwise, the
+// data is read and discarded.
+//
+// If the chunk's data is cut short, goriffa.ErrCorrupted is
+// returned. When seeking, this is only detected if the chunk
+// outlasts the RIFF data's size.
+//
+// If there are no more chunks, io.EOF is returned.
+func (r *Reader) SkipChunk() error {
+	header, _, err := r.readHeader()
+	if err != nil {
+		return err
+	}
+	if !r.root().lenient {
+		return r.skip(r.next - r.bytesRead)
+	}
+
+	// The padding byte may be missing, so leave it to be
+	// skipped along with the next header.
+	start := r.bytesRead
+	err = r.skip(header.Size)
+	if isShort(err) {
+		r.truncate(header, r.bytesRead-start)
+
+		return nil
+	}
+
+	return err
+}
+
 // ReadToEnd will call ReadChunk until io.EOF is returned,
 // building up a slice of chunks. If any other error is
 // returned (i.e. not io.EOF), then all the chunks read
@@ -128,36 +385,561 @@ func (r *Reader) ReadToEnd() ([]internal.Chunk, error) {
 	}
 }
 
+// Identifier returns the FOURCC of the chunk this Reader
+// reads from: "RIFF" for the top-level Reader, or the list's
+// identifier for a Reader returned by ReadList.
+func (r *Reader) Identifier() internal.FourCC {
+	return r.identifier
+}
+
 // FileType returns the parsed file type for the RIFF
-// data.
+// data. For a Reader returned by ReadList, FileType
+// returns the list type.
 func (r *Reader) FileType() internal.FileType {
 	return r.fileType
 }
 
 // Size returns the content length as reported by
-// the RIFF data read.
+// the RIFF data read. If the content length does not
+// fit in 32 bits (i.e. RF64 data), 0xFFFFFFFF is
+// returned; use Size64 instead.
 func (r *Reader) Size() uint32 {
-	return r.size
+	if r.size > math.MaxUint32 {
+		return math.MaxUint32
+	}
+
+	return uint32(r.size)
+}
+
+// Size64 returns the content length as reported by
+// the RIFF data read. Unlike Size, the 64-bit size
+// held by the "ds64" chunk of RF64 data is returned.
+func (r *Reader) Size64() uint64 {
+	return uint64(r.size)
+}
+
+// Offset returns the position within the RIFF data of
+// the next byte to be read: after NextHeader (or
+// NextChunk), the position at which the chunk's data
+// begins.
+func (r *Reader) Offset() int64 {
+	return r.offset()
+}
+
+// ByteOrder returns the byte order of the RIFF data's
+// size fields: binary.BigEndian for "RIFX" data and
+// binary.LittleEndian otherwise.
+func (r *Reader) ByteOrder() binary.ByteOrder {
+	return r.root().byteOrder
+}
+
+// DS64 returns the data of the "ds64" chunk, read by
+// New from RF64 (or BW64) data. If the data is plain
+// RIFF data, false is returned.
+func (r *Reader) DS64() (internal.DS64, bool) {
+	if root := r.root(); root.ds64 != nil {
+		return *root.ds64, true
+	}
+
+	return internal.DS64{}, false
+}
+
+// readHeader will skip whatever remains of the previous
+// chunk and then read and parse the next chunk header,
+// returning the number of bytes read. Filler chunks are
+// skipped as well if SkipFiller is used.
+// Readers over lists return io.EOF once the list has been
+// read entirely.
+func (r *Reader) readHeader() (internal.ChunkHeader, int, error) {
+	if r.peeked != nil {
+		header := *r.peeked
+		r.peeked = nil
+
+		return header, internal.LengthChunkHeader, nil
+	}
+
+	for {
+		header, n, err := r.readNextHeader()
+		if err != nil || !r.root().skipFiller || !goriffa.IsFiller(header.Identifier) {
+			return header, n, err
+		}
+	}
+}
+
+// readNextHeader will skip whatever remains of the previous
+// chunk and then read and parse the next chunk header.
+func (r *Reader) readNextHeader() (internal.ChunkHeader, int, error) {
+	var header internal.ChunkHeader
+	root := r.root()
+	if err := r.skipRest(); err != nil {
+		return header, 0, err
+	}
+	if root.truncated || r.bounded() && r.bytesRead >= r.size {
+		return header, 0, io.EOF
+	}
+	if root.lenient {
+		if err := r.resync(); err != nil {
+			return header, 0, err
+		}
+	}
+
+	var b [internal.LengthChunkHeader]byte
+	n, err := r.read(b[:])
+	if err != nil {
+		return header, n, err
+	} else if n < len(b) {
+		return header, n, errCorruptedTooShort
+	}
+
+	header, err = r.parseHeader(b)
+	if err != nil {
+		return header, n, err
+	}
+	offset := r.offset() - int64(n)
+	if err := root.checkChunk(offset); err != nil {
+		return header, n, err
+	}
+
+	// A chunk outlasting its form may just as well be cut
+	// short, which is told apart once the data ends.
+	if remaining := r.size - r.bytesRead; root.lenient && r.parent != nil && header.Size > remaining {
+		r.warn(WarningOutOfBounds, offset, "chunk %q outlasts its list by %d bytes", header.Identifier, header.Size-remaining)
+		header.Size = remaining
+	}
+	r.next = r.bytesRead + internal.PaddedLength(header.Size)
+	r.padded = header.Size%2 != 0
+
+	return header, n, nil
+}
+
+// skipRest will skip whatever remains of the most recent
+// chunk. A lenient Reader only skips the padding byte if
+// it's there, i.e. unless the next bytes look like the
+// next chunk header instead.
+func (r *Reader) skipRest() error {
+	n := r.next - r.bytesRead
+	root := r.root()
+	if !root.lenient {
+		return r.skip(n)
+	}
+	if root.truncated {
+		return nil
+	}
+
+	if r.padded && n > 0 && (r.parent == nil || r.next < r.size) {
+		if err := r.skip(n - 1); err != nil {
+			return r.recover(err)
+		}
+
+		b, _ := root.r.(*bufio.Reader).Peek(4)
+		missing := len(b) == 0 ||
+			len(b) == 4 && b[0] != 0 && goriffa.IsPrintable(internal.FourCC(internal.Must4Byte(b)))
+		if missing {
+			r.warn(WarningMissingPad, r.offset(), "chunk is missing its padding byte")
+			r.next--
+			r.padded = false
+
+			return nil
+		}
+
+		n = 1
+	}
+
+	return r.recover(r.skip(n))
+}
+
+// resync will discard bytes until the next bytes look like
+// a chunk header, i.e. begin with a printable FOURCC. If
+// there is no such header, io.EOF is returned.
+func (r *Reader) resync() error {
+	root := r.root()
+	offset := r.offset()
+
+	var skipped int64
+	for {
+		b, err := root.r.(*bufio.Reader).Peek(internal.LengthChunkHeader)
+		remaining := int64(len(b))
+		if r.bounded() && r.size-r.bytesRead < remaining {
+			remaining = r.size - r.bytesRead
+		}
+
+		if remaining < int64(internal.LengthChunkHeader) {
+			if err != nil && !errors.Is(err, io.EOF) {
+				return err
+			}
+			if skipped+remaining > 0 {
+				r.warn(WarningTrailingData, offset, "%d bytes of trailing data", skipped+remaining)
+			}
+			if err := r.skip(remaining); err != nil {
+				return err
+			}
+			if r.parent == nil && r.bytesRead < r.size {
+				r.warn(WarningRIFFSize, r.sizeOffset(), "RIFF size (%d) exceeds the data by %d bytes", r.size, r.size-r.bytesRead)
+			}
+
+			return io.EOF
+		}
+
+		if goriffa.IsPrintable(internal.FourCC(internal.Must4Byte(b[:4]))) {
+			if skipped > 0 {
+				r.warn(WarningResync, offset, "skipped %d bytes to find the next chunk", skipped)
+			}
+
+			return nil
+		}
+
+		if err := r.skip(1); err != nil {
+			return err
+		}
+		skipped++
+	}
+}
+
+// truncate will record the chunk with the provided header
+// as truncated after n bytes of its data, after which the
+// Reader returns io.EOF.
+func (r *Reader) truncate(header internal.ChunkHeader, n int64) {
+	r.warn(WarningTruncated, r.offset()-n-int64(internal.LengthChunkHeader), "chunk %q truncated to %d of %d bytes", header.Identifier, n, header.Size)
+	r.root().truncated = true
+	r.next = r.bytesRead
+}
+
+// recover will recover from the provided error if it
+// signals the data is too short, in which case the
+// Reader returns io.EOF from then on.
+func (r *Reader) recover(err error) error {
+	if err == nil || !isShort(err) {
+		return err
+	}
+
+	r.warn(WarningTruncated, r.offset(), "data truncated")
+	r.root().truncated = true
+
+	return nil
+}
+
+// readFull will read len(b) bytes, unless an error occurs.
+// Only a lenient Reader - which reads through a buffer -
+// reads more than once.
+func (r *Reader) readFull(b []byte) (int, error) {
+	if r.root().lenient {
+		return io.ReadFull(readerFunc(r.read), b)
+	}
+
+	return r.read(b)
+}
+
+// warn will record a warning for a lenient Reader.
+func (r *Reader) warn(code WarningCode, offset int64, format string, args ...interface{}) {
+	root := r.root()
+	root.warnings = append(root.warnings, Warning{
+		Code:    code,
+		Offset:  offset,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// parseHeader will parse a chunk header, looking up the
+// 64-bit size of the chunk in the ds64 chunk if needed.
+func (r *Reader) parseHeader(b [internal.LengthChunkHeader]byte) (internal.ChunkHeader, error) {
+	header := internal.ChunkHeader{
+		Identifier: internal.FourCC(internal.Must4Byte(b[:4])),
+		Size:       int64(r.ByteOrder().Uint32(b[4:])),
+	}
+	if ds64 := r.root().ds64; ds64 != nil && header.Size == math.MaxUint32 {
+		if size, ok := ds64.ChunkSize(header.Identifier); ok {
+			if size > math.MaxInt64 {
+				return header, fmt.Errorf("%w: impossibly large chunk size (%d)", internal.ErrCorrupted, size)
+			}
+			header.Size = int64(size)
+		}
+	}
+
+	return header, nil
+}
+
+// readDS64 will read the "ds64" chunk that must follow
+// the header of RF64 data, using its RIFF size in place
+// of the header's.
+func (r *Reader) readDS64() error {
+	var ch internal.Chunk
+	if _, err := r.ReadChunk(&ch); err != nil {
+		return wrap(err)
+	}
+	if ch.Identifier != goriffa.FourCCDS64 {
+		return fmt.Errorf("%w: %s data does not begin with ds64 chunk", internal.ErrCorrupted, r.identifier)
+	}
+
+	ds64, err := internal.ParseDS64(ch.Data)
+	if err != nil {
+		return fmt.Errorf("%w: %s", internal.ErrCorrupted, err)
+	}
+	if ds64.RIFFSize > math.MaxInt64 {
+		return fmt.Errorf("%w: impossibly large file size (%d)", internal.ErrCorrupted, ds64.RIFFSize)
+	}
+
+	r.ds64 = &ds64
+	if r.size == math.MaxUint32 {
+		r.size = int64(ds64.RIFFSize)
+	}
+
+	return nil
+}
+
+// checkChunk will count the chunk towards the chunks read,
+// returning a LimitError if there are too many chunks.
+func (r *Reader) checkChunk(offset int64) error {
+	r.chunks++
+	if r.maxChunks > 0 && r.chunks > r.maxChunks {
+		return &LimitError{
+			Limit:  "chunk count",
+			Value:  int64(r.chunks),
+			Max:    int64(r.maxChunks),
+			Offset: offset,
+		}
+	}
+
+	return nil
+}
+
+// checkSize will return a LimitError if the chunk is too
+// large to be read into memory.
+func (r *Reader) checkSize(header internal.ChunkHeader, offset int64) error {
+	if r.maxChunkSize > 0 && header.Size > r.maxChunkSize {
+		return &LimitError{
+			Limit:  "chunk size",
+			Value:  header.Size,
+			Max:    r.maxChunkSize,
+			Offset: offset,
+		}
+	}
+
+	return nil
+}
+
+// checkDepth will return a LimitError if a list nested
+// to the provided depth exceeds the maximum depth.
+func (r *Reader) checkDepth(depth int, offset int64) error {
+	if r.maxDepth > 0 && depth > r.maxDepth {
+		return &LimitError{
+			Limit:  "list depth",
+			Value:  int64(depth),
+			Max:    int64(r.maxDepth),
+			Offset: offset,
+		}
+	}
+
+	return nil
+}
+
+// offset returns the position of the next byte to be
+// read within the RIFF data.
+func (r *Reader) offset() int64 {
+	return r.start + r.bytesRead
+}
+
+// bounded reports whether the Reader ends at its size, as
+// lists and forms read by Forms do, rather than at the end
+// of the data. A form whose size is impossibly small (see
+// Lenient) lasts until the end of the data regardless.
+func (r *Reader) bounded() bool {
+	return r.parent != nil || r.form && !r.overrun
+}
+
+// sizeOffset returns the position of the RIFF size field,
+// which directly precedes the file type.
+func (r *Reader) sizeOffset() int64 {
+	return r.root().start - 4
+}
+
+// root returns the top-level Reader.
+func (r *Reader) root() *Reader {
+	for r.parent != nil {
+		r = r.parent
+	}
+
+	return r
+}
+
+// skip will skip the next n bytes, seeking if the underlying
+// reader implements io.Seeker and discarding them otherwise.
+func (r *Reader) skip(n int64) error {
+	if n <= 0 {
+		return nil
+	}
+
+	if r.parent != nil {
+		if r.bytesRead+n > internal.PaddedLength(r.size) {
+			return errCorruptedReadOutOfBounds
+		}
+		if err := r.parent.skip(n); err != nil {
+			return err
+		}
+		r.bytesRead += n
+
+		return nil
+	}
+
+	if seeker, ok := r.r.(io.Seeker); ok {
+		if _, err := seeker.Seek(n, io.SeekCurrent); err != nil {
+			return err
+		}
+		r.bytesRead += n
+
+		return r.checkBounds()
+	}
+
+	skipped, err := io.CopyN(io.Discard, readerFunc(r.read), n)
+	if skipped < n && (err == nil || errors.Is(err, io.EOF)) {
+		return errCorruptedTooShort
+	}
+
+	return err
 }
 
 func (r *Reader) read(b []byte) (int, error) {
+	if r.parent != nil {
+		// Never read past the end of the list, as those bytes
+		// belong to the parent.
+		if r.bytesRead+int64(len(b)) > internal.PaddedLength(r.size) {
+			return 0, errCorruptedReadOutOfBounds
+		}
+
+		n, err := r.parent.read(b)
+		r.bytesRead += int64(n)
+
+		return n, err
+	}
+
 	n, err := r.r.Read(b)
 	r.bytesRead += int64(n)
 	if err != nil {
 		return n, err
 	}
 
-	if r.bytesRead > internal.PaddedLength(int64(r.size)) {
-		return n, errCorruptedReadOutOfBounds
+	return n, r.checkBounds()
+}
+
+// checkBounds will return an error if the top-level Reader
+// has read past the RIFF size. A lenient Reader records a
+// warning instead, and keeps reading until the data ends.
+func (r *Reader) checkBounds() error {
+	if r.bytesRead <= internal.PaddedLength(r.size) {
+		return nil
+	}
+	if !r.lenient {
+		return errCorruptedReadOutOfBounds
+	}
+
+	if !r.overrun {
+		r.overrun = true
+		r.warn(WarningRIFFSize, r.sizeOffset(), "data continues past the RIFF size (%d)", r.size)
 	}
 
-	return n, nil
+	return nil
+}
+
+// readData will read n bytes of chunk data via readFull,
+// returning the bytes read along with any error. Memory is
+// allocated as the data is read rather than all up front,
+// as a chunk's size may claim far more data than there is.
+func readData(readFull func([]byte) (int, error), n int64) ([]byte, error) {
+	var data []byte
+	for {
+		// Grow the data by no more than its length so far.
+		step, limit := n-int64(len(data)), int64(len(data))
+		if limit < maxPrealloc {
+			limit = maxPrealloc
+		}
+		if step > limit {
+			step = limit
+		}
+
+		start := len(data)
+		data = append(data, make([]byte, step)...)
+		read, err := readFull(data[start:])
+		data = data[:start+read]
+		if err != nil || int64(len(data)) >= n || int64(read) < step {
+			return data, err
+		}
+	}
+}
+
+// isRIFFPrefix reports whether the FOURCC may begin
+// RIFF data.
+func isRIFFPrefix(prefix internal.FourCC) bool {
+	return prefix == goriffa.FourCCRIFF ||
+		prefix == goriffa.FourCCRIFX ||
+		prefix == goriffa.FourCCRF64 ||
+		prefix == goriffa.FourCCBW64
 }
 
 func wrap(err error) error {
-	if errors.Is(err, internal.ErrBufferUnderflow) || errors.Is(err, io.EOF) {
+	if isShort(err) {
 		return errCorruptedTooShort
 	}
 
 	return err
 }
+
+// isShort reports whether the error signals that the data
+// is shorter than expected.
+func isShort(err error) bool {
+	return errors.Is(err, internal.ErrBufferUnderflow) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, errCorruptedTooShort)
+}
+
+// chunkReader reads the data of a single chunk, as
+// returned by NextChunk.
+type chunkReader struct {
+	r *Reader
+
+	// end holds the Reader's next offset for the chunk
+	// being read; if it changes, the Reader has moved on.
+	end       int64
+	header    internal.ChunkHeader
+	remaining int64
+}
+
+func (cr *chunkReader) Read(b []byte) (int, error) {
+	if cr.r.next != cr.end {
+		return 0, internal.ErrClosed
+	}
+
+	if cr.remaining <= 0 {
+		// Skip the padding byte, if any.
+		if err := cr.r.skipRest(); err != nil {
+			return 0, err
+		}
+		cr.end = cr.r.next
+
+		return 0, io.EOF
+	}
+
+	if int64(len(b)) > cr.remaining {
+		b = b[:cr.remaining]
+	}
+
+	n, err := cr.r.read(b)
+	cr.remaining -= int64(n)
+	if errors.Is(err, io.EOF) && cr.remaining > 0 {
+		if cr.r.root().lenient {
+			cr.r.truncate(cr.header, cr.header.Size-cr.remaining)
+			cr.end = cr.r.next
+
+			return n, io.EOF
+		}
+
+		return n, errCorruptedTooShort
+	}
+
+	return n, err
+}
+
+type readerFunc func([]byte) (int, error)
+
+func (f readerFunc) Read(b []byte) (int, error) {
+	return f(b)
+}
@@ -2,9 +2,11 @@ package reader_test
 
 import (
 	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"testing"
 
 	"github.com/standoffvenus/goriffa"
@@ -33,10 +35,71 @@ func ExampleReader_ReadChunk() {
 	// Chunk { ID: "fmt ", Size: 5, Data: [1 2 3 4 5] }
 }
 
+func ExampleReader_ReadList() {
+	info := goriffa.Chunk{
+		Identifier: internal.FourCC(internal.StringMust4Byte("INAM")),
+		Data:       []byte("Song"),
+	}
+
+	var buf bytes.Buffer
+	buf.Write(header(int64(internal.LengthListHeader) + info.ByteLength()))
+	buf.Write(list(internal.StringMust4Byte("INFO"), info))
+
+	r, rErr := reader.New(&buf)
+	if rErr != nil {
+		panic(rErr)
+	}
+
+	infoReader, listErr := r.ReadList()
+	if listErr != nil {
+		panic(listErr)
+	}
+
+	chunks, readErr := infoReader.ReadToEnd()
+	if readErr != nil {
+		panic(readErr)
+	}
+
+	fmt.Printf("%s %s: %s = %s\n",
+		infoReader.Identifier(),
+		infoReader.FileType(),
+		chunks[0].Identifier,
+		chunks[0].Data)
+	// Output: LIST INFO: INAM = Song
+}
+
+func ExampleReader_NextChunk() {
+	r, rErr := reader.New(RIFFReader())
+	if rErr != nil {
+		panic(rErr)
+	}
+
+	for {
+		header, data, err := r.NextChunk()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			panic(err)
+		}
+
+		// Stream the data anywhere, e.g. to a file or a
+		// decoder, without holding it all in memory.
+		n, copyErr := io.Copy(io.Discard, data)
+		if copyErr != nil {
+			panic(copyErr)
+		}
+
+		fmt.Printf("%q: %d bytes\n", header.Identifier, n)
+	}
+	// Output: "fmt ": 5 bytes
+	// "data": 8 bytes
+}
+
 func TestNew(t *testing.T) {
 	r, err := reader.New(bytes.NewReader(header(0)))
 	assert.NoError(t, err)
 
+	assert.Equal(t, binary.LittleEndian, r.ByteOrder())
 	assert.Equal(t, test.FileType, r.FileType())
 	assert.Equal(t, uint32(4), r.Size())
 }
@@ -71,6 +134,140 @@ func TestNewNoRIFFHeader(t *testing.T) {
 	assert.ErrorIs(t, err, goriffa.ErrCorrupted)
 }
 
+func TestNewRIFX(t *testing.T) {
+	c := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{1, 2, 3}}
+
+	var buf bytes.Buffer
+	buf.Write(goriffa.FourCCRIFX[:])
+	buf.Write([]byte{0, 0, 0, byte(4 + c.ByteLength())})
+	buf.Write(test.FileType[:])
+	buf.Write(c.Identifier[:])
+	buf.Write([]byte{0, 0, 0, 3})
+	buf.Write(internal.Pad(c.Data))
+
+	r, err := reader.New(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, goriffa.FourCCRIFX, r.Identifier())
+	assert.Equal(t, binary.BigEndian, r.ByteOrder())
+	assert.Equal(t, uint32(4+c.ByteLength()), r.Size())
+
+	chunks, readErr := r.ReadToEnd()
+	assert.NoError(t, readErr)
+	assert.Equal(t, []goriffa.Chunk{c}, chunks)
+}
+
+func TestNewRF64(t *testing.T) {
+	for _, prefix := range []internal.FourCC{goriffa.FourCCRF64, goriffa.FourCCBW64} {
+		data := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{1, 2, 3, 4}}
+		smpl := goriffa.Chunk{Identifier: goriffa.FourCCSMPL, Data: []byte{5, 6}}
+		riffSize := uint64(4 + 48 + data.ByteLength() + smpl.ByteLength())
+
+		var buf bytes.Buffer
+		buf.Write(rf64Header(prefix, riffSize, uint64(len(data.Data)), smpl))
+		buf.Write(data.Identifier[:])
+		buf.Write(internal.LittleEndianUInt32Bytes(math.MaxUint32))
+		buf.Write(data.Data)
+		buf.Write(smpl.Identifier[:])
+		buf.Write(internal.LittleEndianUInt32Bytes(math.MaxUint32))
+		buf.Write(smpl.Data)
+
+		r, err := reader.New(&buf)
+		assert.NoError(t, err)
+		assert.Equal(t, prefix, r.Identifier())
+		assert.Equal(t, test.FileType, r.FileType())
+		assert.Equal(t, uint32(riffSize), r.Size())
+		assert.Equal(t, riffSize, r.Size64())
+
+		ds64, ok := r.DS64()
+		assert.True(t, ok)
+		assert.Equal(t, uint64(len(data.Data)), ds64.DataSize)
+
+		chunks, readErr := r.ReadToEnd()
+		assert.NoError(t, readErr)
+		assert.Equal(t, []goriffa.Chunk{data, smpl}, chunks)
+	}
+}
+
+func TestNewRF64LargeSize(t *testing.T) {
+	var buf bytes.Buffer
+	buf.Write(rf64Header(goriffa.FourCCRF64, 1<<33, 1<<33-48))
+	buf.Write(goriffa.FourCCData[:])
+	buf.Write(internal.LittleEndianUInt32Bytes(math.MaxUint32))
+
+	r, err := reader.New(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(math.MaxUint32), r.Size())
+	assert.Equal(t, uint64(1<<33), r.Size64())
+
+	h, _, nextErr := r.NextChunk()
+	assert.NoError(t, nextErr)
+	assert.Equal(t, int64(1<<33-48), h.Size)
+}
+
+func TestNewRF64ImpossibleChunkSize(t *testing.T) {
+	for name, riffSize := range map[string]uint64{
+		// The ds64 chunk size outlasts the RIFF size.
+		"outlasts RIFF": 52,
+		// Both sizes agree, but claim far more data than
+		// there is.
+		"outlasts data": 1<<62 + 40,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			buf.Write(rf64Header(goriffa.FourCCRF64, riffSize, 1<<62))
+			buf.Write(goriffa.FourCCData[:])
+			buf.Write(internal.LittleEndianUInt32Bytes(math.MaxUint32))
+			buf.Write([]byte{1, 2, 3, 4})
+			b := buf.Bytes()
+			assert.Len(t, b, 60)
+
+			r, err := reader.New(bytes.NewReader(b))
+			assert.NoError(t, err)
+			_, err = r.ReadChunk(new(goriffa.Chunk))
+			assert.ErrorIs(t, err, goriffa.ErrCorrupted)
+
+			r, err = reader.New(bytes.NewReader(b), reader.Lenient())
+			assert.NoError(t, err)
+			var c goriffa.Chunk
+			_, err = r.ReadChunk(&c)
+			assert.NoError(t, err)
+			assert.Equal(t, []byte{1, 2, 3, 4}, c.Data)
+			assert.NotEmpty(t, r.Warnings())
+
+			idx, err := reader.NewIndex(bytes.NewReader(b))
+			if err != nil {
+				assert.ErrorIs(t, err, goriffa.ErrCorrupted)
+
+				return
+			}
+			_, err = idx.ReadChunk(idx.Entries()[0])
+			assert.ErrorIs(t, err, goriffa.ErrCorrupted)
+		})
+	}
+}
+
+func TestNewRF64MissingDS64(t *testing.T) {
+	c := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{1, 2}}
+
+	var buf bytes.Buffer
+	buf.Write(goriffa.FourCCRF64[:])
+	buf.Write(internal.LittleEndianUInt32Bytes(math.MaxUint32))
+	buf.Write(test.FileType[:])
+	buf.Write(chunk(c))
+
+	_, err := reader.New(&buf)
+	assert.ErrorIs(t, err, goriffa.ErrCorrupted)
+}
+
+func TestNewPlainRIFFHasNoDS64(t *testing.T) {
+	r, err := reader.New(bytes.NewReader(header(0)))
+	assert.NoError(t, err)
+
+	_, ok := r.DS64()
+	assert.False(t, ok)
+	assert.Equal(t, uint64(r.Size()), r.Size64())
+}
+
 func TestReadChunk(t *testing.T) {
 	expectedChunk := goriffa.Chunk{
 		Identifier: goriffa.FourCCData,
@@ -181,6 +378,342 @@ func TestReadChunkUnderflowOnData(t *testing.T) {
 	mockReader.AssertExpectations(t)
 }
 
+func TestNextChunk(t *testing.T) {
+	first := goriffa.Chunk{Identifier: goriffa.FourCCFormat, Data: []byte{1, 2, 3}}
+	second := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{4, 5}}
+
+	var buf bytes.Buffer
+	buf.Write(header(first.ByteLength() + second.ByteLength()))
+	buf.Write(chunk(first))
+	buf.Write(chunk(second))
+
+	r, err := reader.New(&buf)
+	assert.NoError(t, err)
+
+	for _, expected := range []goriffa.Chunk{first, second} {
+		h, data, nextErr := r.NextChunk()
+		assert.NoError(t, nextErr)
+		assert.Equal(t, expected.Identifier, h.Identifier)
+		assert.Equal(t, int64(len(expected.Data)), h.Size)
+
+		b, readErr := io.ReadAll(data)
+		assert.NoError(t, readErr)
+		assert.Equal(t, expected.Data, b)
+	}
+
+	_, _, eofErr := r.NextChunk()
+	assert.ErrorIs(t, eofErr, io.EOF)
+}
+
+func TestNextChunkPartialRead(t *testing.T) {
+	first := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{1, 2, 3, 4, 5}}
+	second := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{6, 7}}
+
+	var buf bytes.Buffer
+	buf.Write(header(first.ByteLength() + second.ByteLength()))
+	buf.Write(chunk(first))
+	buf.Write(chunk(second))
+
+	r, err := reader.New(&buf)
+	assert.NoError(t, err)
+
+	_, data, nextErr := r.NextChunk()
+	assert.NoError(t, nextErr)
+
+	partial := make([]byte, 2)
+	n, readErr := data.Read(partial)
+	assert.NoError(t, readErr)
+	assert.Equal(t, first.Data[:n], partial[:n])
+
+	var ch goriffa.Chunk
+	_, chErr := r.ReadChunk(&ch)
+	assert.NoError(t, chErr)
+	assert.Equal(t, second, ch)
+
+	_, staleErr := data.Read(partial)
+	assert.ErrorIs(t, staleErr, goriffa.ErrClosed)
+}
+
+func TestNextChunkTruncatedData(t *testing.T) {
+	c := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{1, 2, 3, 4}}
+	chunkBytes := chunk(c)
+
+	var buf bytes.Buffer
+	buf.Write(header(c.ByteLength()))
+	buf.Write(chunkBytes[:len(chunkBytes)-2])
+
+	r, err := reader.New(&buf)
+	assert.NoError(t, err)
+
+	_, data, nextErr := r.NextChunk()
+	assert.NoError(t, nextErr)
+
+	_, readErr := io.ReadAll(data)
+	assert.ErrorIs(t, readErr, goriffa.ErrCorrupted)
+}
+
+func TestNextHeaderAndSkipChunk(t *testing.T) {
+	format := goriffa.Chunk{Identifier: goriffa.FourCCFormat, Data: []byte{1, 2, 3}}
+	smpl := goriffa.Chunk{Identifier: goriffa.FourCCSMPL, Data: []byte{4, 5}}
+	data := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{6, 7, 8, 9}}
+
+	var buf bytes.Buffer
+	buf.Write(header(format.ByteLength() + smpl.ByteLength() + data.ByteLength()))
+	buf.Write(chunk(format))
+	buf.Write(chunk(smpl))
+	buf.Write(chunk(data))
+
+	sources := map[string]io.Reader{
+		"seeker":     &seekCounter{ReadSeeker: bytes.NewReader(buf.Bytes())},
+		"non-seeker": bytes.NewBuffer(buf.Bytes()),
+	}
+	for name, source := range sources {
+		t.Run(name, func(t *testing.T) {
+			r, err := reader.New(source)
+			assert.NoError(t, err)
+
+			for {
+				h, headerErr := r.NextHeader()
+				assert.NoError(t, headerErr)
+
+				// Peeking is idempotent.
+				again, againErr := r.NextHeader()
+				assert.NoError(t, againErr)
+				assert.Equal(t, h, again)
+
+				if h.Identifier == goriffa.FourCCData {
+					break
+				}
+				assert.NoError(t, r.SkipChunk())
+			}
+
+			var ch goriffa.Chunk
+			n, readErr := r.ReadChunk(&ch)
+			assert.NoError(t, readErr)
+			assert.Equal(t, int(data.ByteLength()), n)
+			assert.Equal(t, data, ch)
+
+			assert.ErrorIs(t, r.SkipChunk(), io.EOF)
+			_, eofErr := r.NextHeader()
+			assert.ErrorIs(t, eofErr, io.EOF)
+
+			if seeker, ok := source.(*seekCounter); ok {
+				assert.Equal(t, 2, seeker.seeks)
+			}
+		})
+	}
+}
+
+func TestOffset(t *testing.T) {
+	format := goriffa.Chunk{Identifier: goriffa.FourCCFormat, Data: []byte{1, 2, 3}}
+	name := goriffa.Chunk{Identifier: fourCC("INAM"), Data: []byte("Song")}
+	info := list(internal.StringMust4Byte("INFO"), name)
+
+	var buf bytes.Buffer
+	buf.Write(header(format.ByteLength() + int64(len(info))))
+	buf.Write(chunk(format))
+	buf.Write(info)
+
+	r, err := reader.New(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), r.Offset())
+
+	_, err = r.NextHeader()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20), r.Offset())
+
+	assert.NoError(t, r.SkipChunk())
+	_, err = r.NextHeader()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(32), r.Offset())
+
+	l, err := r.ReadList()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, int64(36), l.Offset())
+
+	_, err = l.NextHeader()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(44), l.Offset())
+}
+
+func TestNextHeaderThenReadList(t *testing.T) {
+	listBytes := list(internal.StringMust4Byte("INFO"),
+		goriffa.Chunk{Identifier: fourCC("INAM"), Data: []byte("name")})
+
+	var buf bytes.Buffer
+	buf.Write(header(int64(len(listBytes))))
+	buf.Write(listBytes)
+
+	r, err := reader.New(&buf)
+	assert.NoError(t, err)
+
+	h, headerErr := r.NextHeader()
+	assert.NoError(t, headerErr)
+	assert.True(t, goriffa.IsList(h.Identifier))
+
+	listReader, listErr := r.ReadList()
+	assert.NoError(t, listErr)
+	assert.Equal(t, internal.FileType(internal.StringMust4Byte("INFO")), listReader.FileType())
+
+	// Skipping within the list stays within its bounds.
+	assert.NoError(t, listReader.SkipChunk())
+	assert.ErrorIs(t, listReader.SkipChunk(), io.EOF)
+}
+
+func TestSkipChunkOutOfBounds(t *testing.T) {
+	c := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{1, 2, 3, 4}}
+
+	var buf bytes.Buffer
+	buf.Write(header(c.ByteLength() - 2))
+	buf.Write(chunk(c))
+
+	r, err := reader.New(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.ErrorIs(t, r.SkipChunk(), goriffa.ErrCorrupted)
+}
+
+func TestSkipChunkTruncated(t *testing.T) {
+	c := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{1, 2, 3, 4}}
+	chunkBytes := chunk(c)
+
+	var buf bytes.Buffer
+	buf.Write(header(c.ByteLength()))
+	buf.Write(chunkBytes[:len(chunkBytes)-1])
+
+	r, err := reader.New(&buf)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, r.SkipChunk(), goriffa.ErrCorrupted)
+}
+
+func TestReadList(t *testing.T) {
+	name := goriffa.Chunk{Identifier: fourCC("INAM"), Data: []byte("odd")}
+	comment := goriffa.Chunk{Identifier: fourCC("ICMT"), Data: []byte("even")}
+	data := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{1, 2}}
+	listBytes := list(internal.StringMust4Byte("INFO"), name, comment)
+
+	var buf bytes.Buffer
+	buf.Write(header(int64(len(listBytes)) + data.ByteLength()))
+	buf.Write(listBytes)
+	buf.Write(chunk(data))
+
+	r, err := reader.New(&buf)
+	assert.NoError(t, err)
+
+	listReader, listErr := r.ReadList()
+	assert.NoError(t, listErr)
+	assert.Equal(t, goriffa.FourCCList, listReader.Identifier())
+	assert.Equal(t, internal.FileType(internal.StringMust4Byte("INFO")), listReader.FileType())
+	assert.Equal(t, uint32(len(listBytes)-internal.LengthChunkHeader), listReader.Size())
+
+	chunks, readErr := listReader.ReadToEnd()
+	assert.NoError(t, readErr)
+	assert.Equal(t, []goriffa.Chunk{name, comment}, chunks)
+
+	var ch goriffa.Chunk
+	_, chErr := r.ReadChunk(&ch)
+	assert.NoError(t, chErr)
+	assert.Equal(t, data, ch)
+
+	_, eofErr := r.ReadChunk(&ch)
+	assert.ErrorIs(t, eofErr, io.EOF)
+}
+
+func TestReadListNested(t *testing.T) {
+	inner := goriffa.Chunk{Identifier: fourCC("strf"), Data: []byte{1, 2, 3}}
+	strl := list(internal.StringMust4Byte("strl"), inner)
+	hdrl := list(internal.StringMust4Byte("hdrl"), goriffa.Chunk{
+		Identifier: goriffa.FourCCList,
+		Data:       strl[internal.LengthChunkHeader:],
+	})
+
+	var buf bytes.Buffer
+	buf.Write(header(int64(len(hdrl))))
+	buf.Write(hdrl)
+
+	r, err := reader.New(&buf)
+	assert.NoError(t, err)
+
+	hdrlReader, hdrlErr := r.ReadList()
+	assert.NoError(t, hdrlErr)
+
+	strlReader, strlErr := hdrlReader.ReadList()
+	assert.NoError(t, strlErr)
+	assert.Equal(t, internal.FileType(internal.StringMust4Byte("strl")), strlReader.FileType())
+
+	var ch goriffa.Chunk
+	_, chErr := strlReader.ReadChunk(&ch)
+	assert.NoError(t, chErr)
+	assert.Equal(t, inner, ch)
+
+	_, strlEOF := strlReader.ReadChunk(&ch)
+	assert.ErrorIs(t, strlEOF, io.EOF)
+
+	_, hdrlEOF := hdrlReader.ReadChunk(&ch)
+	assert.ErrorIs(t, hdrlEOF, io.EOF)
+}
+
+func TestReadListSkipsUnreadSubChunks(t *testing.T) {
+	listBytes := list(internal.StringMust4Byte("INFO"),
+		goriffa.Chunk{Identifier: fourCC("INAM"), Data: []byte("name")},
+		goriffa.Chunk{Identifier: fourCC("ICMT"), Data: []byte("comment")})
+	data := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{1, 2, 3}}
+
+	var buf bytes.Buffer
+	buf.Write(header(int64(len(listBytes)) + data.ByteLength()))
+	buf.Write(listBytes)
+	buf.Write(chunk(data))
+
+	r, err := reader.New(&buf)
+	assert.NoError(t, err)
+
+	listReader, listErr := r.ReadList()
+	assert.NoError(t, listErr)
+
+	var ch goriffa.Chunk
+	_, partialErr := listReader.ReadChunk(&ch)
+	assert.NoError(t, partialErr)
+
+	_, chErr := r.ReadChunk(&ch)
+	assert.NoError(t, chErr)
+	assert.Equal(t, data, ch)
+}
+
+func TestReadListNotAList(t *testing.T) {
+	data := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{1, 2}}
+
+	var buf bytes.Buffer
+	buf.Write(header(data.ByteLength()))
+	buf.Write(chunk(data))
+
+	r, err := reader.New(&buf)
+	assert.NoError(t, err)
+
+	_, listErr := r.ReadList()
+	assert.ErrorIs(t, listErr, goriffa.ErrBadChunk)
+}
+
+func TestReadListSubChunkOutOfBounds(t *testing.T) {
+	listBytes := list(internal.StringMust4Byte("INFO"),
+		goriffa.Chunk{Identifier: fourCC("INAM"), Data: []byte("name")})
+	// Shrink the list so its sub-chunk no longer fits.
+	copy(listBytes[4:], internal.LittleEndianUInt32Bytes(8))
+
+	var buf bytes.Buffer
+	buf.Write(header(int64(len(listBytes))))
+	buf.Write(listBytes)
+
+	r, err := reader.New(&buf)
+	assert.NoError(t, err)
+
+	listReader, listErr := r.ReadList()
+	assert.NoError(t, listErr)
+
+	_, readErr := listReader.ReadChunk(new(goriffa.Chunk))
+	assert.ErrorIs(t, readErr, goriffa.ErrCorrupted)
+}
+
 func header(size int64) []byte {
 	headerBytes := make([]byte, 0, 12)
 	buf := bytes.NewBuffer(headerBytes[:])
@@ -196,6 +729,26 @@ func header(size int64) []byte {
 	return buf.Bytes()
 }
 
+func rf64Header(prefix internal.FourCC, riffSize, dataSize uint64, large ...internal.Chunk) []byte {
+	var ds64 []byte
+	ds64 = append(ds64, internal.LittleEndianUInt64Bytes(riffSize)...)
+	ds64 = append(ds64, internal.LittleEndianUInt64Bytes(dataSize)...)
+	ds64 = append(ds64, internal.LittleEndianUInt64Bytes(0)...)
+	ds64 = append(ds64, internal.LittleEndianUInt32Bytes(uint32(len(large)))...)
+	for _, c := range large {
+		ds64 = append(ds64, c.Identifier[:]...)
+		ds64 = append(ds64, internal.LittleEndianUInt64Bytes(uint64(len(c.Data)))...)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(prefix[:])
+	buf.Write(internal.LittleEndianUInt32Bytes(math.MaxUint32))
+	buf.Write(test.FileType[:])
+	buf.Write(chunk(internal.Chunk{Identifier: goriffa.FourCCDS64, Data: ds64}))
+
+	return buf.Bytes()
+}
+
 func chunk(c internal.Chunk) []byte {
 	return append(
 		c.Identifier[:],
@@ -204,12 +757,39 @@ func chunk(c internal.Chunk) []byte {
 			internal.Pad(c.Data)...)...)
 }
 
+func list(listType internal.FileType, chunks ...internal.Chunk) []byte {
+	data := append([]byte{}, listType[:]...)
+	for _, c := range chunks {
+		data = append(data, chunk(c)...)
+	}
+
+	return chunk(internal.Chunk{
+		Identifier: goriffa.FourCCList,
+		Data:       data,
+	})
+}
+
+func fourCC(s string) internal.FourCC {
+	return internal.FourCC(internal.StringMust4Byte(s))
+}
+
 func expectNew(r *MockReader, fileSize uint32) {
 	r.PrepareRead(goriffa.FourCCRIFF[:], nil)
 	r.PrepareRead(internal.LittleEndianUInt32Bytes(fileSize), nil)
 	r.PrepareRead(test.FileType[:], nil)
 }
 
+type seekCounter struct {
+	io.ReadSeeker
+	seeks int
+}
+
+func (s *seekCounter) Seek(offset int64, whence int) (int64, error) {
+	s.seeks++
+
+	return s.ReadSeeker.Seek(offset, whence)
+}
+
 type MockReader struct {
 	mock.Mock
 }
@@ -0,0 +1,100 @@
+package reader
+
+import (
+	"errors"
+	"io"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/internal"
+)
+
+var (
+	// SkipChunk may be returned by a WalkFunc to skip the
+	// chunk it was called for: if the chunk is a list, its
+	// sub-chunks are not visited. Returned for any other
+	// chunk, SkipChunk has no effect.
+	SkipChunk error = errors.New("skip this chunk")
+
+	// SkipList may be returned by a WalkFunc to skip the
+	// remaining chunks of the list holding the chunk it
+	// was called for (along with the chunk's sub-chunks,
+	// if it's a list). Returned for a top-level chunk,
+	// SkipList ends the walk.
+	SkipList error = errors.New("skip the rest of this list")
+)
+
+// WalkFunc is the type of the function called by Walk
+// for every chunk.
+//
+// The path holds the identifiers of the lists enclosing
+// the chunk, followed by the chunk's own identifier - all
+// separated by "/", with the list type of lists between
+// brackets, e.g.:
+//  LIST[INFO]/INAM
+//
+// If the chunk is not a list, data reads its data; just
+// like the reader returned by Reader.NextChunk, it's only
+// valid until the function returns, and whatever is left
+// unread is skipped. If the chunk is a list, data is nil.
+//
+// If the function returns an error other than SkipChunk
+// and SkipList (or errors wrapping them), Walk stops and
+// returns it.
+type WalkFunc func(path string, header internal.ChunkHeader, data io.Reader) error
+
+// Walk will call fn for every remaining chunk read by the
+// provided Reader, in the order the chunks are stored -
+// including the sub-chunks of lists, which are visited
+// right after the list itself. The data of chunks is only
+// read if fn reads it.
+//
+// Any error returned by fn or encountered while reading
+// the data (other than io.EOF) is returned.
+func Walk(r *Reader, fn WalkFunc) error {
+	if err := walk(r, "", fn); !errors.Is(err, SkipList) {
+		return err
+	}
+
+	return nil
+}
+
+func walk(r *Reader, prefix string, fn WalkFunc) error {
+	for {
+		header, err := r.NextHeader()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if !goriffa.IsList(header.Identifier) {
+			_, data, err := r.NextChunk()
+			if err != nil {
+				return err
+			}
+			if err := fn(prefix+header.Identifier.String(), header, data); err != nil && !errors.Is(err, SkipChunk) {
+				return err
+			}
+
+			continue
+		}
+
+		list, err := r.ReadList()
+		if err != nil {
+			return err
+		}
+
+		path := prefix + header.Identifier.String() + "[" + list.FileType().String() + "]"
+		switch err := fn(path, header, nil); {
+		case err == nil:
+			// Stopping early within the list only skips the
+			// rest of the list.
+			if err := walk(list, path+"/", fn); err != nil && !errors.Is(err, SkipList) {
+				return err
+			}
+		case errors.Is(err, SkipChunk):
+		default:
+			return err
+		}
+	}
+}
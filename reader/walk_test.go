@@ -0,0 +1,213 @@
+package reader_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/internal"
+	"github.com/standoffvenus/goriffa/reader"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleWalk() {
+	r, err := reader.New(bytes.NewReader(walkedRIFF()))
+	if err != nil {
+		panic(err)
+	}
+
+	err = reader.Walk(r, func(path string, header goriffa.ChunkHeader, data io.Reader) error {
+		switch path {
+		case "LIST[hdrl]":
+			// Nothing of interest in there.
+			return reader.SkipChunk
+		case "LIST[INFO]/INAM":
+			name, err := io.ReadAll(data)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s: %s\n", path, name)
+		default:
+			fmt.Printf("%s (%d bytes)\n", path, header.Size)
+		}
+
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// Output:
+	// fmt  (3 bytes)
+	// LIST[INFO] (30 bytes)
+	// LIST[INFO]/INAM: Song
+	// LIST[INFO]/ICMT (5 bytes)
+	// data (2 bytes)
+}
+
+func TestWalk(t *testing.T) {
+	paths, err := walkPaths(walkedRIFF(), nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"fmt ",
+		"LIST[hdrl]",
+		"LIST[hdrl]/avih",
+		"LIST[hdrl]/LIST[strl]",
+		"LIST[hdrl]/LIST[strl]/strh",
+		"LIST[hdrl]/LIST[strl]/strf",
+		"LIST[INFO]",
+		"LIST[INFO]/INAM",
+		"LIST[INFO]/ICMT",
+		"data",
+	}, paths)
+}
+
+func TestWalkData(t *testing.T) {
+	r, err := reader.New(bytes.NewReader(walkedRIFF()))
+	assert.NoError(t, err)
+
+	data := make(map[string][]byte)
+	assert.NoError(t, reader.Walk(r, func(path string, header goriffa.ChunkHeader, chunkData io.Reader) error {
+		if goriffa.IsList(header.Identifier) {
+			assert.Nil(t, chunkData, path)
+
+			return nil
+		}
+
+		// Leave the data of odd-sized chunks partially read.
+		n := int64(header.Size)
+		if n%2 != 0 {
+			n--
+		}
+		b, err := io.ReadAll(io.LimitReader(chunkData, n))
+		data[path] = b
+
+		return err
+	}))
+
+	assert.Equal(t, map[string][]byte{
+		"fmt ":                       {1, 2},
+		"LIST[hdrl]/avih":            []byte("main"),
+		"LIST[hdrl]/LIST[strl]/strh": []byte("stream"),
+		"LIST[hdrl]/LIST[strl]/strf": []byte("form"),
+		"LIST[INFO]/INAM":            []byte("Song"),
+		"LIST[INFO]/ICMT":            []byte("grea"),
+		"data":                       {4, 5},
+	}, data)
+}
+
+func TestWalkSkipChunk(t *testing.T) {
+	paths, err := walkPaths(walkedRIFF(), map[string]error{
+		"LIST[hdrl]/LIST[strl]": reader.SkipChunk,
+		"LIST[INFO]":            reader.SkipChunk,
+		"data":                  reader.SkipChunk,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"fmt ",
+		"LIST[hdrl]",
+		"LIST[hdrl]/avih",
+		"LIST[hdrl]/LIST[strl]",
+		"LIST[INFO]",
+		"data",
+	}, paths)
+}
+
+func TestWalkSkipList(t *testing.T) {
+	paths, err := walkPaths(walkedRIFF(), map[string]error{
+		"LIST[hdrl]/LIST[strl]/strh": reader.SkipList,
+		"LIST[hdrl]/LIST[strl]":      reader.SkipList,
+		"LIST[INFO]/INAM":            reader.SkipList,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"fmt ",
+		"LIST[hdrl]",
+		"LIST[hdrl]/avih",
+		"LIST[hdrl]/LIST[strl]",
+		"LIST[INFO]",
+		"LIST[INFO]/INAM",
+		"data",
+	}, paths)
+
+	// At the top level, SkipList ends the walk.
+	paths, err = walkPaths(walkedRIFF(), map[string]error{"LIST[hdrl]": reader.SkipList})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"fmt ", "LIST[hdrl]"}, paths)
+}
+
+func TestWalkSkipWrapped(t *testing.T) {
+	paths, err := walkPaths(walkedRIFF(), map[string]error{
+		"LIST[hdrl]": fmt.Errorf("no headers: %w", reader.SkipChunk),
+		"LIST[INFO]": fmt.Errorf("no metadata: %w", reader.SkipList),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"fmt ", "LIST[hdrl]", "LIST[INFO]"}, paths)
+}
+
+func TestWalkError(t *testing.T) {
+	errStop := errors.New("stop")
+
+	paths, err := walkPaths(walkedRIFF(), map[string]error{"LIST[hdrl]/LIST[strl]/strh": errStop})
+
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, "LIST[hdrl]/LIST[strl]/strh", paths[len(paths)-1])
+}
+
+func TestWalkCorrupted(t *testing.T) {
+	data := walkedRIFF()
+
+	paths, err := walkPaths(data[:len(data)-4], nil)
+
+	assert.ErrorIs(t, err, goriffa.ErrCorrupted)
+	assert.Equal(t, "LIST[INFO]/ICMT", paths[len(paths)-1])
+}
+
+// walkPaths walks the provided RIFF data, returning the
+// paths visited. The function walking the data returns
+// the error provided for a path, if any.
+func walkPaths(b []byte, errs map[string]error) ([]string, error) {
+	r, err := reader.New(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	err = reader.Walk(r, func(path string, _ goriffa.ChunkHeader, _ io.Reader) error {
+		paths = append(paths, path)
+
+		return errs[path]
+	})
+
+	return paths, err
+}
+
+func walkedRIFF() []byte {
+	strl := list(internal.StringMust4Byte("strl"),
+		goriffa.Chunk{Identifier: fourCC("strh"), Data: []byte("stream")},
+		goriffa.Chunk{Identifier: fourCC("strf"), Data: []byte("form")})
+	hdrl := list(internal.StringMust4Byte("hdrl"),
+		goriffa.Chunk{Identifier: fourCC("avih"), Data: []byte("main")},
+		goriffa.Chunk{Identifier: goriffa.FourCCList, Data: strl[internal.LengthChunkHeader:]})
+
+	var buf bytes.Buffer
+	for _, b := range [][]byte{
+		chunk(goriffa.Chunk{Identifier: goriffa.FourCCFormat, Data: []byte{1, 2, 3}}),
+		hdrl,
+		list(internal.StringMust4Byte("INFO"),
+			goriffa.Chunk{Identifier: fourCC("INAM"), Data: []byte("Song")},
+			goriffa.Chunk{Identifier: fourCC("ICMT"), Data: []byte("great")}),
+		chunk(goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{4, 5}}),
+	} {
+		buf.Write(b)
+	}
+
+	return append(header(int64(buf.Len())), buf.Bytes()...)
+}
@@ -0,0 +1,64 @@
+package reader
+
+import "fmt"
+
+// WarningCode identifies the kind of problem a lenient
+// Reader recovered from.
+type WarningCode string
+
+// Warning codes recorded by a lenient Reader.
+const (
+	// WarningRIFFSize is recorded when the RIFF size
+	// disagrees with the length of the data, e.g. a RIFF
+	// size of 0 or 0xFFFFFFFF left by a crashed recorder.
+	WarningRIFFSize WarningCode = "riff-size"
+
+	// WarningMissingPad is recorded when a chunk of odd
+	// size is not followed by a padding byte.
+	WarningMissingPad WarningCode = "missing-pad"
+
+	// WarningResync is recorded when bytes had to be
+	// skipped to find the next chunk header.
+	WarningResync WarningCode = "resync"
+
+	// WarningTruncated is recorded when the data ends
+	// before a chunk does.
+	WarningTruncated WarningCode = "truncated"
+
+	// WarningOutOfBounds is recorded when a chunk
+	// outlasts the list it belongs to; the chunk is cut
+	// short at the end of the list.
+	WarningOutOfBounds WarningCode = "out-of-bounds"
+
+	// WarningTrailingData is recorded when the data ends
+	// with bytes too few to hold a chunk.
+	WarningTrailingData WarningCode = "trailing-data"
+)
+
+// Warning describes a problem with RIFF data that a
+// lenient Reader (see Lenient) recovered from.
+type Warning struct {
+	Code WarningCode
+
+	// Offset holds the position within the RIFF data
+	// at which the problem was found.
+	Offset int64
+
+	// Message describes the problem.
+	Message string
+}
+
+// Warnings returns the warnings recorded while reading
+// the RIFF data so far, in the order they were recorded.
+// Only a lenient Reader (see Lenient) records warnings.
+func (r *Reader) Warnings() []Warning {
+	warnings := r.root().warnings
+	copied := make([]Warning, len(warnings))
+	copy(copied, warnings)
+
+	return copied
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s at offset %d: %s", w.Code, w.Offset, w.Message)
+}
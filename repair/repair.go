@@ -0,0 +1,253 @@
+// Package repair provides a mechanism for repairing
+// damaged RIFF data, e.g. files left behind by crashed
+// recorders.
+package repair
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/internal"
+	"github.com/standoffvenus/goriffa/reader"
+	"github.com/standoffvenus/goriffa/writer"
+)
+
+// Change describes a change made to the RIFF data
+// while repairing it.
+type Change struct {
+	// Offset holds the position within the damaged
+	// RIFF data the change was made at.
+	Offset int64
+
+	// Description describes the change.
+	Description string
+}
+
+// tempFileThreshold is the amount of data held in memory
+// when the repaired data is written to a plain io.Writer,
+// see writer.TempFileThreshold.
+const tempFileThreshold = 32 * 1024 * 1024
+
+// Repair will write a repaired copy of the damaged RIFF
+// data held by src - which is size bytes long - to dst,
+// returning every change made. The data is read by a
+// lenient reader (see reader.Lenient), so every problem
+// it recovers from is fixed, and in particular:
+//
+// - The RIFF size and the sizes of lists are fixed.
+//
+// - The size of a top-level "data" chunk is fixed from
+// the length of src, if it's 0xFFFFFFFF, outlasts src or
+// is followed by bytes that don't belong to a chunk (such
+// as samples recorded after its size was last written,
+// e.g. a size of 0). The chunk's data then lasts until the
+// end of src.
+//
+// - Trailing chunks cut short are removed.
+//
+// - Missing padding bytes are inserted.
+//
+// If dst implements writer.WriterWithWriterAt, the
+// repaired data is written to it directly; otherwise,
+// it's spooled (see writer.NewBuffered) first.
+func Repair(dst io.Writer, src io.ReaderAt, size int64) ([]Change, error) {
+	r, err := reader.New(io.NewSectionReader(src, 0, size), reader.Lenient())
+	if err != nil {
+		return nil, err
+	}
+
+	// The "ds64" chunk (or the "JUNK" chunk reserved in
+	// its place) is written by the writer itself.
+	var reserved int64
+	options := []writer.Option{writer.ByteOrder(r.ByteOrder())}
+	if id := r.Identifier(); id == goriffa.FourCCRF64 || id == goriffa.FourCCBW64 {
+		options = append(options, writer.PromoteToRF64())
+		reserved = int64(internal.LengthChunkHeader + internal.LengthDS64)
+	}
+
+	var w *writer.Writer
+	if at, ok := dst.(writer.WriterWithWriterAt); ok {
+		w, err = writer.New(at, r.FileType(), options...)
+	} else {
+		options = append(options, writer.TempFileThreshold(tempFileThreshold))
+		w, err = writer.NewBuffered(dst, r.FileType(), options...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rp := &repairer{src: src, size: size}
+	content, copyErr := rp.copyList(r, w, true)
+	if copyErr != nil {
+		_ = w.Close()
+
+		return rp.changes, copyErr
+	}
+	if err := w.Close(); err != nil {
+		return rp.changes, err
+	}
+
+	if riffSize := int64(len(r.FileType())) + reserved + content; uint64(riffSize) != r.Size64() {
+		rp.change(int64(len(r.Identifier())), "fixed RIFF size from %d to %d", r.Size64(), riffSize)
+	}
+
+	return rp.changes, nil
+}
+
+// repairer holds the state of a single repair.
+type repairer struct {
+	src     io.ReaderAt
+	size    int64
+	changes []Change
+
+	// warnings holds the number of the reader's warnings
+	// turned into changes so far.
+	warnings int
+}
+
+// copyList will copy the chunks read by r to w, returning
+// the number of bytes copied.
+func (rp *repairer) copyList(r *reader.Reader, w *writer.Writer, top bool) (int64, error) {
+	var content int64
+	for {
+		header, err := r.NextHeader()
+		rp.collect(r)
+		if errors.Is(err, io.EOF) {
+			return content, nil
+		} else if err != nil {
+			return content, err
+		}
+
+		offset := r.Offset()
+		headerOffset := offset - int64(internal.LengthChunkHeader)
+
+		if top && header.Identifier == goriffa.FourCCData && rp.overrun(header, offset) {
+			size := rp.size - offset
+			rp.change(headerOffset, "fixed %q size from %d to %d", header.Identifier, header.Size, size)
+
+			n, err := rp.copyChunk(w, header.Identifier, io.NewSectionReader(rp.src, offset, size))
+
+			// The rest of src has been copied.
+			return content + n, err
+		}
+
+		if !goriffa.IsList(header.Identifier) && offset+header.Size > rp.size {
+			rp.change(headerOffset, "removed %q chunk cut short (%d of %d bytes)", header.Identifier, rp.size-offset, header.Size)
+
+			return content, nil
+		}
+
+		if !goriffa.IsList(header.Identifier) {
+			_, data, err := r.NextChunk()
+			if err != nil {
+				return content, err
+			}
+
+			n, err := rp.copyChunk(w, header.Identifier, data)
+			content += n
+			if err != nil {
+				return content, err
+			}
+
+			continue
+		}
+
+		l, err := r.ReadList()
+		if err != nil {
+			return content, err
+		}
+		lw, err := w.CreateList(l.FileType())
+		if err != nil {
+			return content, err
+		}
+
+		listContent, err := rp.copyList(l, lw, false)
+		if err != nil {
+			return content, err
+		}
+		if err := lw.Close(); err != nil {
+			return content, err
+		}
+
+		listSize := int64(len(l.FileType())) + listContent
+		if listSize != header.Size {
+			rp.change(headerOffset, "fixed %q size from %d to %d", header.Identifier, header.Size, listSize)
+		}
+		content += internal.ChunkHeader{Size: listSize}.ByteLength()
+	}
+}
+
+// copyChunk will copy a chunk with the provided identifier
+// and data to w, returning the number of bytes copied.
+func (rp *repairer) copyChunk(w *writer.Writer, identifier internal.FourCC, data io.Reader) (int64, error) {
+	cw, err := w.BeginChunk(identifier)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := io.Copy(cw, data)
+	if err != nil {
+		return 0, err
+	}
+	if err := cw.Close(); err != nil {
+		return 0, err
+	}
+
+	return internal.ChunkHeader{Size: n}.ByteLength(), nil
+}
+
+// overrun reports whether the size of the "data" chunk
+// with the provided header - whose data begins at offset
+// - can't be right, in which case the chunk's data is
+// assumed to last until the end of src.
+func (rp *repairer) overrun(header internal.ChunkHeader, offset int64) bool {
+	if header.Size == math.MaxUint32 || offset+header.Size > rp.size {
+		return true
+	}
+
+	end := offset + header.Size
+	if end == rp.size {
+		return false
+	}
+
+	// Unless the data is followed by another chunk - with
+	// or without a padding byte - it must go on.
+	for _, next := range []int64{internal.PaddedLength(header.Size), header.Size} {
+		var id internal.FourCC
+		if n, _ := rp.src.ReadAt(id[:], offset+next); n == len(id) && goriffa.IsPrintable(id) {
+			return false
+		}
+	}
+
+	return offset+internal.PaddedLength(header.Size) < rp.size
+}
+
+// collect will turn the warnings recorded by the reader
+// since the last call into changes.
+func (rp *repairer) collect(r *reader.Reader) {
+	warnings := r.Warnings()
+	for _, w := range warnings[rp.warnings:] {
+		switch w.Code {
+		case reader.WarningRIFFSize, reader.WarningTruncated:
+			// Reported once the repair is done, or as
+			// the chunk is removed.
+		case reader.WarningMissingPad:
+			rp.change(w.Offset, "inserted missing padding byte")
+		case reader.WarningOutOfBounds:
+			rp.change(w.Offset, "cut chunk short at the end of its list (%s)", w.Message)
+		default:
+			rp.change(w.Offset, "removed unreadable data (%s)", w.Message)
+		}
+	}
+	rp.warnings = len(warnings)
+}
+
+func (rp *repairer) change(offset int64, format string, args ...interface{}) {
+	rp.changes = append(rp.changes, Change{
+		Offset:      offset,
+		Description: fmt.Sprintf(format, args...),
+	})
+}
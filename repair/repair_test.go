@@ -0,0 +1,170 @@
+package repair_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/internal"
+	"github.com/standoffvenus/goriffa/internal/test"
+	"github.com/standoffvenus/goriffa/repair"
+	"github.com/standoffvenus/goriffa/writer"
+	"github.com/stretchr/testify/assert"
+)
+
+// fileType is the type of the damaged RIFF data, so it
+// matches the data the repairs are expected to produce.
+var fileType = string(test.FileType[:])
+
+func Example() {
+	// A recording whose sizes were never written.
+	samples := []byte{0x00, 0x80, 0xFF, 0x7F, 0x01, 0x80}
+	damaged := test.RIFFWithSize(fileType, 0,
+		test.Chunk("fmt ", "\x01\x00\x02\x00"),
+		test.Header("data", 0), samples)
+
+	var repaired bytes.Buffer
+	changes, err := repair.Repair(&repaired, bytes.NewReader(damaged), int64(len(damaged)))
+	if err != nil {
+		panic(err)
+	}
+
+	for _, c := range changes {
+		fmt.Printf("offset %d: %s\n", c.Offset, c.Description)
+	}
+
+	// Output:
+	// offset 24: fixed "data" size from 0 to 6
+	// offset 4: fixed RIFF size from 0 to 30
+}
+
+func TestRepairCrashedRecorder(t *testing.T) {
+	samples := bytes.Repeat([]byte{0x01, 0x80, 0xFE}, 5)
+	damaged := test.RIFFWithSize(fileType, 0xFFFFFFFF,
+		test.Chunk("fmt ", "\x01\x00"),
+		test.Header("data", 0), samples)
+
+	repaired, changes := repairBytes(t, damaged)
+	assert.Equal(t, expected(t,
+		goriffa.Chunk{Identifier: goriffa.FourCCFormat, Data: []byte{1, 0}},
+		goriffa.Chunk{Identifier: goriffa.FourCCData, Data: samples}), repaired)
+	assert.Equal(t, []repair.Change{
+		{Offset: 22, Description: `fixed "data" size from 0 to 15`},
+		{Offset: 4, Description: "fixed RIFF size from 4294967295 to 38"},
+	}, changes)
+}
+
+func TestRepairDataOutlastsFile(t *testing.T) {
+	damaged := test.RIFFWithSize(fileType, 4+8+100, test.Header("data", 100), []byte{1, 2, 3, 4})
+
+	repaired, changes := repairBytes(t, damaged)
+	assert.Equal(t, expected(t, goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{1, 2, 3, 4}}), repaired)
+	assert.Equal(t, []repair.Change{
+		{Offset: 12, Description: `fixed "data" size from 100 to 4`},
+		{Offset: 4, Description: "fixed RIFF size from 112 to 16"},
+	}, changes)
+}
+
+func TestRepairMissingPadAndTruncatedChunk(t *testing.T) {
+	// The odd "fmt " chunk lacks its padding byte and the
+	// trailing "smpl" chunk is cut short.
+	damaged := test.RIFFWithSize(fileType, 4+11+12+8+10,
+		test.Unpadded("fmt ", "\x01\x02\x03"),
+		test.Chunk("LIST", "INFO"),
+		test.Chunk("data", "\x04\x05"),
+		test.Header("smpl", 10), []byte{1, 2, 3})
+
+	repaired, changes := repairBytes(t, damaged)
+
+	var want test.Buffer
+	w, err := writer.New(&want, test.FileType)
+	assert.NoError(t, err)
+	_, err = w.WriteChunk(goriffa.Chunk{Identifier: goriffa.FourCCFormat, Data: []byte{1, 2, 3}})
+	assert.NoError(t, err)
+	_, err = w.CreateList(internal.StringMust4Byte("INFO"))
+	assert.NoError(t, err)
+	_, err = w.WriteChunk(goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{4, 5}})
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	assert.Equal(t, want.Bytes(), repaired)
+	assert.Equal(t, []repair.Change{
+		{Offset: 23, Description: "inserted missing padding byte"},
+		{Offset: 45, Description: `removed "smpl" chunk cut short (3 of 10 bytes)`},
+		{Offset: 4, Description: "fixed RIFF size from 45 to 38"},
+	}, changes)
+}
+
+func TestRepairTruncatedList(t *testing.T) {
+	name := test.Chunk("INAM", "Song")
+	damaged := test.RIFFWithSize(fileType, 4+8+44,
+		test.Header("LIST", 44), []byte("INFO"), name, test.Header("ICMT", 20))
+
+	repaired, changes := repairBytes(t, damaged)
+
+	var want test.Buffer
+	w, err := writer.New(&want, test.FileType)
+	assert.NoError(t, err)
+	info, err := w.CreateList(internal.StringMust4Byte("INFO"))
+	assert.NoError(t, err)
+	_, err = info.WriteChunk(goriffa.Chunk{Identifier: test.FourCC("INAM"), Data: []byte("Song")})
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	assert.Equal(t, want.Bytes(), repaired)
+	assert.Equal(t, []repair.Change{
+		{Offset: 36, Description: `removed "ICMT" chunk cut short (0 of 20 bytes)`},
+		{Offset: 12, Description: `fixed "LIST" size from 44 to 16`},
+		{Offset: 4, Description: "fixed RIFF size from 56 to 28"},
+	}, changes)
+}
+
+func TestRepairIntactFile(t *testing.T) {
+	r, _ := test.WAV()
+	b, err := io.ReadAll(r)
+	assert.NoError(t, err)
+
+	repaired, changes := repairBytes(t, b)
+	assert.Empty(t, changes)
+	assert.Equal(t, b, repaired)
+}
+
+func TestRepairToFile(t *testing.T) {
+	damaged := test.RIFFWithSize(fileType, 0, test.Chunk("data", "\x01\x02"))
+
+	f, err := os.CreateTemp(t.TempDir(), "")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	changes, err := repair.Repair(f, bytes.NewReader(damaged), int64(len(damaged)))
+	assert.NoError(t, err)
+	assert.Len(t, changes, 1)
+
+	repaired, err := os.ReadFile(f.Name())
+	assert.NoError(t, err)
+	assert.Equal(t, expected(t, goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{1, 2}}), repaired)
+}
+
+func TestRepairNotRIFF(t *testing.T) {
+	b := []byte("not RIFF data at all")
+	_, err := repair.Repair(new(bytes.Buffer), bytes.NewReader(b), int64(len(b)))
+	assert.ErrorIs(t, err, goriffa.ErrCorrupted)
+}
+
+func repairBytes(t *testing.T, b []byte) ([]byte, []repair.Change) {
+	var buf bytes.Buffer
+	changes, err := repair.Repair(&buf, bytes.NewReader(b), int64(len(b)))
+	assert.NoError(t, err)
+
+	return buf.Bytes(), changes
+}
+
+func expected(t *testing.T, chunks ...goriffa.Chunk) []byte {
+	var buf bytes.Buffer
+	assert.NoError(t, writer.WriteChunks(&buf, test.FileType, chunks))
+
+	return buf.Bytes()
+}
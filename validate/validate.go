@@ -0,0 +1,485 @@
+// Package validate provides a mechanism for checking RIFF
+// data against the rules of the RIFF format - and of
+// specific forms, such as Wavefiles - reporting every
+// problem found rather than stopping at the first.
+package validate
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/internal"
+	"github.com/standoffvenus/goriffa/wave"
+)
+
+// Severity describes how severe a Finding is.
+type Severity int
+
+const (
+	// SeverityWarning is used for findings that most
+	// readers cope with, e.g. a non-zero padding byte.
+	SeverityWarning Severity = iota + 1
+
+	// SeverityError is used for findings that break the
+	// rules of the RIFF format (or of a form).
+	SeverityError
+)
+
+// Code identifies the rule a Finding breaks.
+type Code string
+
+// Codes of the rules checked by Validate.
+const (
+	// CodeHeader is used when the data does not begin
+	// with a RIFF (or RIFX, RF64 or BW64) header.
+	CodeHeader Code = "header"
+
+	// CodeRIFFSize is used when the RIFF size exceeds
+	// the length of the data.
+	CodeRIFFSize Code = "riff-size"
+
+	// CodeTrailingData is used for bytes following the
+	// RIFF data - or the last chunk of a list - that
+	// don't belong to a chunk.
+	CodeTrailingData Code = "trailing-data"
+
+	// CodeChunkSize is used when a chunk outlasts the
+	// list it belongs to, or the data.
+	CodeChunkSize Code = "chunk-size"
+
+	// CodeListSize is used when a list is too small to
+	// hold its list type.
+	CodeListSize Code = "list-size"
+
+	// CodeListDepth is used when lists are nested more
+	// deeply than MaxDepth, in which case the chunks of
+	// the innermost list are not checked.
+	CodeListDepth Code = "list-depth"
+
+	// CodeMissingPad is used when a chunk of odd size is
+	// not followed by a padding byte.
+	CodeMissingPad Code = "missing-pad"
+
+	// CodePadByte is used when a padding byte is not 0.
+	CodePadByte Code = "pad-byte"
+
+	// CodeFourCC is used for FOURCCs (including file and
+	// list types) not made up of printable ASCII, see
+	// goriffa.IsPrintable.
+	CodeFourCC Code = "fourcc"
+
+	// CodeWAVEFormat is used when a Wavefile lacks a
+	// "fmt " chunk, or its "fmt " chunk follows the
+	// "data" chunk.
+	CodeWAVEFormat Code = "wave-fmt"
+
+	// CodeWAVEData is used when a Wavefile lacks a
+	// "data" chunk.
+	CodeWAVEData Code = "wave-data"
+)
+
+// Finding describes a problem with RIFF data.
+type Finding struct {
+	Severity Severity `json:"severity"`
+	Code     Code     `json:"code"`
+
+	// Offset holds the position within the RIFF data
+	// at which the problem was found.
+	Offset int64 `json:"offset"`
+
+	// Message describes the problem.
+	Message string `json:"message"`
+}
+
+// MaxDepth is how deeply Validate checks nested lists; a
+// list that is not within another list has a depth of 1.
+const MaxDepth = 64
+
+// formRules holds the rules specific to a form, which
+// are checked against the form's top-level chunks.
+var formRules = map[internal.FileType]func(chunks []chunk) []Finding{
+	wave.FileTypeWavefile: checkWAVE,
+}
+
+// chunk describes a chunk found while validating.
+type chunk struct {
+	identifier internal.FourCC
+	offset     int64
+}
+
+// Validate will check the RIFF data held by r - which is
+// size bytes long - returning every problem found, in
+// the order they appear within the data:
+//
+// - The RIFF size must not exceed the data, and no data
+// may follow the RIFF data.
+//
+// - Chunks must not outlast the lists they belong to,
+// and lists must hold their list type. Lists nested more
+// deeply than MaxDepth are reported rather than checked.
+//
+// - Chunks of odd size must be followed by a padding
+// byte, which should be 0.
+//
+// - FOURCCs must be made up of printable ASCII.
+//
+// - Wavefiles must hold a "fmt " chunk followed by a
+// "data" chunk.
+//
+// An error is only returned if r can't be read.
+func Validate(r io.ReaderAt, size int64) ([]Finding, error) {
+	v := &validator{r: r, size: size, byteOrder: binary.LittleEndian}
+
+	end, fileType, err := v.header()
+	if err != nil || end < 0 {
+		return v.findings, err
+	}
+
+	chunks, err := v.scan(int64(internal.LengthListHeader), end, 0)
+	if err != nil {
+		return v.findings, err
+	}
+
+	if rules, ok := formRules[fileType]; ok {
+		v.findings = append(v.findings, rules(chunks)...)
+	}
+
+	return v.findings, nil
+}
+
+// HasErrors reports whether any of the findings is an
+// error, see SeverityError.
+func HasErrors(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validator holds the state of a single validation.
+type validator struct {
+	r         io.ReaderAt
+	size      int64
+	byteOrder binary.ByteOrder
+	ds64      *internal.DS64
+	findings  []Finding
+}
+
+// header will check the RIFF header, returning the offset
+// at which the RIFF data ends and its file type. If the
+// header is unusable, -1 is returned instead.
+func (v *validator) header() (int64, internal.FileType, error) {
+	var b [internal.LengthListHeader]byte
+	if ok, err := v.readAt(b[:], 0); err != nil || !ok {
+		v.add(SeverityError, CodeHeader, 0, "data too short (%d bytes) to hold a RIFF header", v.size)
+
+		return -1, internal.FileType{}, err
+	}
+
+	identifier := internal.FourCC(internal.Must4Byte(b[:4]))
+	fileType := internal.Must4Byte(b[8:])
+	switch identifier {
+	case goriffa.FourCCRIFF, goriffa.FourCCRF64, goriffa.FourCCBW64:
+	case goriffa.FourCCRIFX:
+		v.byteOrder = binary.BigEndian
+	default:
+		v.add(SeverityError, CodeHeader, 0, "data begins with %q rather than a RIFF header", identifier)
+
+		return -1, fileType, nil
+	}
+
+	riffSize := int64(v.byteOrder.Uint32(b[4:]))
+	if identifier == goriffa.FourCCRF64 || identifier == goriffa.FourCCBW64 {
+		ds64, err := v.readDS64()
+		if err != nil || ds64 == nil {
+			return -1, fileType, err
+		}
+		v.ds64 = ds64
+		if riffSize == math.MaxUint32 && ds64.RIFFSize <= math.MaxInt64-uint64(internal.LengthChunkHeader) {
+			riffSize = int64(ds64.RIFFSize)
+		}
+	}
+
+	if !goriffa.IsPrintable(internal.FourCC(fileType)) {
+		v.add(SeverityError, CodeFourCC, int64(len(identifier)+4), "file type %q isn't printable ASCII", fileType)
+	}
+
+	end := int64(internal.LengthChunkHeader) + riffSize
+	switch {
+	case riffSize < int64(len(fileType)):
+		v.add(SeverityError, CodeRIFFSize, int64(len(identifier)), "impossibly small RIFF size (%d)", riffSize)
+		end = v.size
+	case end > v.size:
+		v.add(SeverityError, CodeRIFFSize, int64(len(identifier)), "RIFF size (%d) exceeds the data by %d bytes", riffSize, end-v.size)
+		end = v.size
+	case internal.PaddedLength(end) < v.size:
+		v.add(SeverityError, CodeTrailingData, internal.PaddedLength(end), "%d bytes of data follow the RIFF data", v.size-internal.PaddedLength(end))
+	}
+
+	return end, fileType, nil
+}
+
+// readDS64 will read the "ds64" chunk following the header
+// of RF64 data. If it's missing, nil is returned.
+func (v *validator) readDS64() (*internal.DS64, error) {
+	offset := int64(internal.LengthListHeader)
+
+	var b [internal.LengthChunkHeader]byte
+	ok, err := v.readAt(b[:], offset)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || internal.FourCC(internal.Must4Byte(b[:4])) != goriffa.FourCCDS64 {
+		v.add(SeverityError, CodeHeader, offset, "RF64 data does not begin with a \"ds64\" chunk")
+
+		return nil, nil
+	}
+
+	// Don't trust the size before it's known to fit.
+	if size := int64(v.byteOrder.Uint32(b[4:])); size <= v.size-offset-int64(len(b)) {
+		data := make([]byte, size)
+		if _, err := v.readAt(data, offset+int64(len(b))); err != nil {
+			return nil, err
+		}
+		if ds64, err := internal.ParseDS64(data); err == nil {
+			return &ds64, nil
+		}
+	}
+
+	v.add(SeverityError, CodeHeader, offset, "unreadable \"ds64\" chunk")
+
+	return nil, nil
+}
+
+// scan will check the chunks between the offsets start
+// and end - held by a list nested to the provided depth,
+// or 0 for the top-level chunks - returning them. Chunks
+// outlasting end are reported and cut short.
+func (v *validator) scan(start, end int64, depth int) ([]chunk, error) {
+	var chunks []chunk
+	for offset := start; offset < end; {
+		if end-offset < int64(internal.LengthChunkHeader) {
+			v.add(SeverityError, CodeTrailingData, offset, "%d bytes too few to hold a chunk", end-offset)
+
+			return chunks, nil
+		}
+
+		var b [internal.LengthChunkHeader]byte
+		if _, err := v.readAt(b[:], offset); err != nil {
+			return chunks, err
+		}
+
+		identifier := internal.FourCC(internal.Must4Byte(b[:4]))
+		size := int64(v.byteOrder.Uint32(b[4:]))
+		if v.ds64 != nil && size == math.MaxUint32 {
+			if ds64Size, ok := v.ds64.ChunkSize(identifier); ok && ds64Size <= math.MaxInt64/2 {
+				size = int64(ds64Size)
+			}
+		}
+		chunks = append(chunks, chunk{identifier: identifier, offset: offset})
+
+		if !goriffa.IsPrintable(identifier) {
+			v.add(SeverityError, CodeFourCC, offset, "chunk identifier %q isn't printable ASCII", identifier)
+		}
+
+		dataOffset := offset + int64(len(b))
+		dataEnd := dataOffset + size
+		if dataEnd > end {
+			if end == v.size {
+				v.add(SeverityError, CodeChunkSize, offset, "chunk %q outlasts the data by %d bytes", identifier, dataEnd-end)
+			} else if depth == 0 {
+				v.add(SeverityError, CodeChunkSize, offset, "chunk %q outlasts the RIFF data by %d bytes", identifier, dataEnd-end)
+			} else {
+				v.add(SeverityError, CodeChunkSize, offset, "chunk %q outlasts its list by %d bytes", identifier, dataEnd-end)
+			}
+
+			return chunks, nil
+		}
+
+		if goriffa.IsList(identifier) {
+			if err := v.list(identifier, offset, size, depth+1); err != nil {
+				return chunks, err
+			}
+		}
+
+		next, err := v.pad(identifier, dataEnd, size, end)
+		if err != nil {
+			return chunks, err
+		}
+		offset = next
+	}
+
+	return chunks, nil
+}
+
+// list will check the list whose header is at offset,
+// nested to the provided depth.
+func (v *validator) list(identifier internal.FourCC, offset, size int64, depth int) error {
+	if size < 4 {
+		v.add(SeverityError, CodeListSize, offset, "%q size (%d) too small to hold a list type", identifier, size)
+
+		return nil
+	}
+
+	dataOffset := offset + int64(internal.LengthChunkHeader)
+
+	var listType internal.FourCC
+	if _, err := v.readAt(listType[:], dataOffset); err != nil {
+		return err
+	}
+	if !goriffa.IsPrintable(listType) {
+		v.add(SeverityError, CodeFourCC, dataOffset, "list type %q isn't printable ASCII", listType)
+	}
+
+	if depth > MaxDepth {
+		v.add(SeverityError, CodeListDepth, offset, "%q nested more deeply than %d lists", identifier, MaxDepth)
+
+		return nil
+	}
+
+	_, err := v.scan(dataOffset+int64(len(listType)), dataOffset+size, depth)
+
+	return err
+}
+
+// pad will check the padding byte of the chunk of the
+// provided size whose data ends at dataEnd, within a list
+// ending at end. The offset of the next chunk is returned.
+func (v *validator) pad(identifier internal.FourCC, dataEnd, size, end int64) (int64, error) {
+	if size%2 == 0 {
+		return dataEnd, nil
+	}
+	if dataEnd == end {
+		v.add(SeverityError, CodeMissingPad, dataEnd, "chunk %q is missing its padding byte", identifier)
+
+		return dataEnd, nil
+	}
+
+	var b [5]byte
+	n := len(b)
+	if end-dataEnd < int64(n) {
+		n = int(end - dataEnd)
+	}
+	if _, err := v.readAt(b[:n], dataEnd); err != nil {
+		return dataEnd, err
+	}
+	if b[0] == 0 {
+		return dataEnd + 1, nil
+	}
+
+	// Unless a chunk header follows the padding byte, the
+	// next chunk presumably begins where the byte should.
+	if n == len(b) &&
+		goriffa.IsPrintable(internal.FourCC(internal.Must4Byte(b[:4]))) &&
+		!goriffa.IsPrintable(internal.FourCC(internal.Must4Byte(b[1:]))) {
+		v.add(SeverityError, CodeMissingPad, dataEnd, "chunk %q is missing its padding byte", identifier)
+
+		return dataEnd, nil
+	}
+
+	v.add(SeverityWarning, CodePadByte, dataEnd, "padding byte of chunk %q is %#02x rather than 0", identifier, b[0])
+
+	return dataEnd + 1, nil
+}
+
+// checkWAVE checks the rules of Wavefiles ("WAVE").
+func checkWAVE(chunks []chunk) []Finding {
+	var findings []Finding
+
+	format, data := -1, -1
+	for i, c := range chunks {
+		if c.identifier == goriffa.FourCCFormat && format == -1 {
+			format = i
+		}
+		if c.identifier == goriffa.FourCCData && data == -1 {
+			data = i
+		}
+	}
+
+	switch {
+	case format == -1:
+		findings = append(findings, finding(SeverityError, CodeWAVEFormat, int64(internal.LengthListHeader), "Wavefile holds no \"fmt \" chunk"))
+	case data != -1 && format > data:
+		findings = append(findings, finding(SeverityError, CodeWAVEFormat, chunks[format].offset, "\"fmt \" chunk follows the \"data\" chunk"))
+	}
+	if data == -1 {
+		findings = append(findings, finding(SeverityError, CodeWAVEData, int64(internal.LengthListHeader), "Wavefile holds no \"data\" chunk"))
+	}
+
+	return findings
+}
+
+// readAt will read len(b) bytes at offset, reporting
+// whether there were enough bytes. Only errors other
+// than io.EOF are returned.
+func (v *validator) readAt(b []byte, offset int64) (bool, error) {
+	if offset+int64(len(b)) > v.size {
+		return false, nil
+	}
+
+	n, err := v.r.ReadAt(b, offset)
+	if n == len(b) {
+		return true, nil
+	}
+	if err == nil || errors.Is(err, io.EOF) {
+		return false, nil
+	}
+
+	return false, err
+}
+
+func (v *validator) add(severity Severity, code Code, offset int64, format string, args ...interface{}) {
+	v.findings = append(v.findings, finding(severity, code, offset, format, args...))
+}
+
+func finding(severity Severity, code Code, offset int64, format string, args ...interface{}) Finding {
+	return Finding{
+		Severity: severity,
+		Code:     code,
+		Offset:   offset,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// MarshalText encodes the severity as its name, e.g.
+// "error".
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a severity encoded by
+// MarshalText.
+func (s *Severity) UnmarshalText(b []byte) error {
+	switch string(b) {
+	case "warning":
+		*s = SeverityWarning
+	case "error":
+		*s = SeverityError
+	default:
+		return fmt.Errorf("unknown severity %q", b)
+	}
+
+	return nil
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s at offset %d: %s [%s]", f.Severity, f.Offset, f.Message, f.Code)
+}
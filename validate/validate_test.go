@@ -0,0 +1,278 @@
+package validate_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/standoffvenus/goriffa/internal/test"
+	"github.com/standoffvenus/goriffa/validate"
+	"github.com/stretchr/testify/assert"
+)
+
+func Example() {
+	data := test.RIFFOf("WAVE",
+		test.Chunk("data", "1234"),
+		test.Unpadded("fmt ", "odd"),
+		test.Chunk("ICMT", "hi"),
+		[]byte("xy"))
+
+	findings, err := validate.Validate(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		panic(err)
+	}
+
+	for _, f := range findings {
+		fmt.Println(f)
+	}
+	fmt.Println(validate.HasErrors(findings))
+
+	// Output:
+	// error at offset 35: chunk "fmt " is missing its padding byte [missing-pad]
+	// error at offset 45: 2 bytes too few to hold a chunk [trailing-data]
+	// error at offset 24: "fmt " chunk follows the "data" chunk [wave-fmt]
+	// true
+}
+
+func TestValidateWAV(t *testing.T) {
+	wav, _ := test.WAV()
+	r := wav.(*bytes.Reader)
+
+	findings, err := validate.Validate(r, r.Size())
+	assert.NoError(t, err)
+	assert.Empty(t, findings)
+}
+
+func TestValidateWEBP(t *testing.T) {
+	webp, _ := test.WEBP()
+	r := webp.(*bytes.Reader)
+
+	findings, err := validate.Validate(r, r.Size())
+	assert.NoError(t, err)
+	assert.Empty(t, findings)
+}
+
+func TestValidateNested(t *testing.T) {
+	assert.Empty(t, validateBytes(t, test.RIFFOf("abcd",
+		test.Chunk("abcd", "odd"),
+		test.List("INFO",
+			test.Chunk("INAM", "name"),
+			test.List("sub ", test.Chunk("ICMT", "x"))))))
+}
+
+func TestValidateRIFX(t *testing.T) {
+	data := test.RIFFOf("abcd", test.Chunk("abcd", "1234"))
+	copy(data, "RIFX")
+	copy(data[4:], []byte{0, 0, 0, 16})
+	copy(data[16:], []byte{0, 0, 0, 4})
+
+	assert.Empty(t, validateBytes(t, data))
+}
+
+func TestValidateNotRIFF(t *testing.T) {
+	assert.Equal(t, []validate.Code{validate.CodeHeader}, codes(validateBytes(t, []byte("not RIFF data"))))
+	assert.Equal(t, []validate.Code{validate.CodeHeader}, codes(validateBytes(t, []byte("RIFF"))))
+}
+
+func TestValidateRIFFSize(t *testing.T) {
+	findings := validateBytes(t, test.RIFFWithSize("abcd", 100, test.Chunk("abcd", "1234")))
+
+	assert.Equal(t, []validate.Finding{{
+		Severity: validate.SeverityError,
+		Code:     validate.CodeRIFFSize,
+		Offset:   4,
+		Message:  "RIFF size (100) exceeds the data by 84 bytes",
+	}}, findings)
+
+	findings = validateBytes(t, test.RIFFWithSize("abcd", 0, test.Chunk("abcd", "1234")))
+	assert.Equal(t, []validate.Code{validate.CodeRIFFSize}, codes(findings))
+}
+
+func TestValidateTrailingData(t *testing.T) {
+	data := append(test.RIFFOf("abcd", test.Chunk("abcd", "1234")), "garbage"...)
+
+	assert.Equal(t, []validate.Finding{{
+		Severity: validate.SeverityError,
+		Code:     validate.CodeTrailingData,
+		Offset:   24,
+		Message:  "7 bytes of data follow the RIFF data",
+	}}, validateBytes(t, data))
+}
+
+func TestValidateOddRIFFSize(t *testing.T) {
+	// The padding byte of the RIFF data itself is no
+	// trailing data.
+	data := test.RIFFWithSize("abcd", 4+11, test.Unpadded("abcd", "odd"), []byte{0})
+
+	assert.Equal(t, []validate.Code{validate.CodeMissingPad}, codes(validateBytes(t, data)))
+}
+
+func TestValidateChunkSize(t *testing.T) {
+	findings := validateBytes(t, test.RIFFOf("abcd",
+		test.List("INFO",
+			test.Header("INAM", 100), []byte("name")),
+		test.Chunk("abcd", "1234")))
+	assert.Equal(t, []validate.Finding{{
+		Severity: validate.SeverityError,
+		Code:     validate.CodeChunkSize,
+		Offset:   24,
+		Message:  `chunk "INAM" outlasts its list by 96 bytes`,
+	}}, findings)
+
+	findings = validateBytes(t, test.RIFFOf("abcd", test.Header("abcd", 100), []byte("1234")))
+	assert.Equal(t, []validate.Code{validate.CodeChunkSize}, codes(findings))
+	assert.Contains(t, findings[0].Message, "outlasts the data")
+}
+
+func TestValidateListSize(t *testing.T) {
+	findings := validateBytes(t, test.RIFFOf("abcd", test.Header("LIST", 2), []byte("ab")))
+
+	assert.Equal(t, []validate.Code{validate.CodeListSize}, codes(findings))
+	assert.Equal(t, int64(12), findings[0].Offset)
+}
+
+func TestValidateListDepth(t *testing.T) {
+	nested := test.Chunk("abcd", "even")
+	for i := 0; i < validate.MaxDepth+1; i++ {
+		nested = test.List("abcd", nested)
+	}
+
+	findings := validateBytes(t, test.RIFFOf("abcd", nested))
+
+	// The innermost list goes unchecked.
+	assert.Equal(t, []validate.Code{validate.CodeListDepth}, codes(findings))
+	assert.Equal(t, int64(12+validate.MaxDepth*12), findings[0].Offset)
+	assert.True(t, validate.HasErrors(findings))
+}
+
+func TestValidateMissingPad(t *testing.T) {
+	findings := validateBytes(t, test.RIFFOf("abcd",
+		test.Unpadded("abcd", "odd"),
+		test.Chunk("efgh", "1234")))
+
+	assert.Equal(t, []validate.Finding{{
+		Severity: validate.SeverityError,
+		Code:     validate.CodeMissingPad,
+		Offset:   23,
+		Message:  `chunk "abcd" is missing its padding byte`,
+	}}, findings)
+}
+
+func TestValidateMissingPadAtEndOfList(t *testing.T) {
+	findings := validateBytes(t, test.RIFFOf("abcd",
+		test.Header("LIST", 4+11), []byte("INFO"), test.Unpadded("INAM", "odd"), []byte{0},
+		test.Chunk("efgh", "1234")))
+
+	assert.Equal(t, []validate.Code{validate.CodeMissingPad}, codes(findings))
+	assert.Equal(t, int64(35), findings[0].Offset)
+}
+
+func TestValidatePadByte(t *testing.T) {
+	data := test.RIFFOf("abcd",
+		test.Chunk("abcd", "odd"),
+		test.Chunk("efgh", "1234"))
+	data[23] = 0xFF
+
+	assert.Equal(t, []validate.Finding{{
+		Severity: validate.SeverityWarning,
+		Code:     validate.CodePadByte,
+		Offset:   23,
+		Message:  `padding byte of chunk "abcd" is 0xff rather than 0`,
+	}}, validateBytes(t, data))
+}
+
+func TestValidateFourCC(t *testing.T) {
+	findings := validateBytes(t, test.RIFFOf("\x00bcd",
+		test.Chunk(" bcd", "1234"),
+		test.List("IN\x7fO", test.Header("b\ncd", 0))))
+
+	assert.Equal(t, []validate.Code{
+		validate.CodeFourCC,
+		validate.CodeFourCC,
+		validate.CodeFourCC,
+		validate.CodeFourCC,
+	}, codes(findings))
+	assert.Equal(t, []int64{8, 12, 32, 36}, offsets(findings))
+	assert.False(t, hasWarnings(findings))
+}
+
+func TestValidateWAVE(t *testing.T) {
+	format := test.Chunk("fmt ", "0123456789abcdef")
+
+	assert.Empty(t, validateBytes(t, test.RIFFOf("WAVE", format, test.Chunk("data", "1234"))))
+
+	findings := validateBytes(t, test.RIFFOf("WAVE", test.Chunk("data", "1234"), format))
+	assert.Equal(t, []validate.Finding{{
+		Severity: validate.SeverityError,
+		Code:     validate.CodeWAVEFormat,
+		Offset:   24,
+		Message:  `"fmt " chunk follows the "data" chunk`,
+	}}, findings)
+
+	findings = validateBytes(t, test.RIFFOf("WAVE", test.List("INFO", format)))
+	assert.Equal(t, []validate.Code{validate.CodeWAVEFormat, validate.CodeWAVEData}, codes(findings))
+}
+
+func TestHasErrors(t *testing.T) {
+	assert.False(t, validate.HasErrors(nil))
+	assert.False(t, validate.HasErrors([]validate.Finding{{Severity: validate.SeverityWarning}}))
+	assert.True(t, validate.HasErrors([]validate.Finding{
+		{Severity: validate.SeverityWarning},
+		{Severity: validate.SeverityError},
+	}))
+}
+
+func TestFindingJSON(t *testing.T) {
+	f := validate.Finding{
+		Severity: validate.SeverityWarning,
+		Code:     validate.CodePadByte,
+		Offset:   23,
+		Message:  "message",
+	}
+
+	b, err := json.Marshal(f)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"severity": "warning", "code": "pad-byte", "offset": 23, "message": "message"}`, string(b))
+
+	var decoded validate.Finding
+	assert.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, f, decoded)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"severity": "fatal"}`), &decoded))
+}
+
+func validateBytes(t *testing.T, b []byte) []validate.Finding {
+	findings, err := validate.Validate(bytes.NewReader(b), int64(len(b)))
+	assert.NoError(t, err)
+
+	return findings
+}
+
+func codes(findings []validate.Finding) []validate.Code {
+	codes := make([]validate.Code, len(findings))
+	for i, f := range findings {
+		codes[i] = f.Code
+	}
+
+	return codes
+}
+
+func offsets(findings []validate.Finding) []int64 {
+	offsets := make([]int64, len(findings))
+	for i, f := range findings {
+		offsets[i] = f.Offset
+	}
+
+	return offsets
+}
+
+func hasWarnings(findings []validate.Finding) bool {
+	for _, f := range findings {
+		if f.Severity == validate.SeverityWarning {
+			return true
+		}
+	}
+
+	return false
+}
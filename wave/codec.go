@@ -0,0 +1,48 @@
+package wave
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/codec"
+)
+
+// The "fmt " chunk of Wavefiles is decoded as a Format by
+// the codec package (see codec.Decode).
+func init() {
+	codec.Register(FileTypeWavefile, goriffa.FourCCFormat, codec.Codec{
+		Decode: decodeFormat,
+		Encode: encodeFormat,
+	})
+}
+
+// decodeFormat decodes the data of a "fmt " chunk. Any
+// bytes following the first 16 (e.g. the extension of
+// WAVEFORMATEX) are ignored.
+func decodeFormat(data []byte, order binary.ByteOrder) (interface{}, error) {
+	return parseFormat(data, order)
+}
+
+// encodeFormat encodes a Format (or *Format) as the data
+// of a "fmt " chunk.
+func encodeFormat(v interface{}, order binary.ByteOrder) ([]byte, error) {
+	var f Format
+	switch concrete := v.(type) {
+	case Format:
+		f = concrete
+	case *Format:
+		if concrete == nil {
+			return nil, fmt.Errorf("%w: nil *Format", codec.ErrUnsupportedValue)
+		}
+		f = *concrete
+	default:
+		return nil, fmt.Errorf("%w: %T is no Format", codec.ErrUnsupportedValue, v)
+	}
+
+	if err := Validate(f); err != nil {
+		return nil, err
+	}
+
+	return formatDataBytes(f, order), nil
+}
@@ -0,0 +1,66 @@
+package wave_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/codec"
+	"github.com/standoffvenus/goriffa/wave"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatCodec(t *testing.T) {
+	format := wave.Format{
+		AudioFormat:   wave.PCM,
+		Channels:      2,
+		SampleRate:    44100,
+		BitsPerSample: 16,
+	}
+
+	c, err := codec.Encode(wave.FileTypeWavefile, goriffa.FourCCFormat, format, binary.LittleEndian)
+	assert.NoError(t, err)
+	assert.Equal(t, goriffa.Chunk{Identifier: goriffa.FourCCFormat, Data: fmtExample[8:]}, c)
+
+	v, err := codec.Decode(wave.FileTypeWavefile, c, binary.LittleEndian)
+	assert.NoError(t, err)
+	assert.Equal(t, format, v)
+
+	// RIFX Wavefiles hold big-endian format data.
+	c, err = codec.Encode(wave.FileTypeWavefile, goriffa.FourCCFormat, &format, binary.BigEndian)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0, 1, 0, 2, 0, 0, 0xAC, 0x44}, c.Data[:8])
+
+	v, err = codec.Decode(wave.FileTypeWavefile, c, binary.BigEndian)
+	assert.NoError(t, err)
+	assert.Equal(t, format, v)
+}
+
+func TestFormatCodecExtended(t *testing.T) {
+	// WAVEFORMATEX appends the size of an extension, which
+	// is ignored.
+	data := append(append([]byte(nil), fmtExample[8:]...), 0, 0)
+
+	v, err := codec.Decode(wave.FileTypeWavefile, goriffa.Chunk{Identifier: goriffa.FourCCFormat, Data: data}, binary.LittleEndian)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(44100), v.(wave.Format).SampleRate)
+}
+
+func TestFormatCodecErrors(t *testing.T) {
+	_, err := codec.Decode(wave.FileTypeWavefile, goriffa.Chunk{Identifier: goriffa.FourCCFormat, Data: fmtExample[8:20]}, binary.LittleEndian)
+	assert.ErrorIs(t, err, wave.ErrBadWaveData)
+
+	data := append([]byte(nil), fmtExample[8:]...)
+	data[bytesPerSecondOffset-8]++
+	_, err = codec.Decode(wave.FileTypeWavefile, goriffa.Chunk{Identifier: goriffa.FourCCFormat, Data: data}, binary.LittleEndian)
+	assert.ErrorIs(t, err, wave.ErrInconsistentBytesPerSecond)
+
+	_, err = codec.Encode(wave.FileTypeWavefile, goriffa.FourCCFormat, wave.Format{}, binary.LittleEndian)
+	assert.ErrorIs(t, err, wave.ErrBadWaveData)
+
+	_, err = codec.Encode(wave.FileTypeWavefile, goriffa.FourCCFormat, "PCM", binary.LittleEndian)
+	assert.ErrorIs(t, err, codec.ErrUnsupportedValue)
+
+	_, err = codec.Encode(wave.FileTypeWavefile, goriffa.FourCCFormat, (*wave.Format)(nil), binary.LittleEndian)
+	assert.ErrorIs(t, err, codec.ErrUnsupportedValue)
+}
@@ -9,10 +9,11 @@
 package wave
 
 import (
-	"bytes"
+	"encoding/binary"
 	"fmt"
 
 	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/encoding"
 	"github.com/standoffvenus/goriffa/internal"
 )
 
@@ -182,17 +183,28 @@ func FormatFromReader(r goriffa.Reader) (Format, error) {
 			string(goriffa.FourCCFormat[:]))
 	}
 
-	// Once we've reached this point, we know that the
-	// chunk's data should be the valid length to do
-	// the below operations; ergo, we don't need to worry
-	// about panics.
-	buffer := bytes.NewReader(ch.Data)
-	f.AudioFormat = AudioFormat(internal.ReadLittleEndianUInt16(buffer))
-	f.Channels = internal.ReadLittleEndianUInt16(buffer)
-	f.SampleRate = internal.ReadLittleEndianUInt32(buffer)
-	expectedBytesPerSecond := internal.ReadLittleEndianUInt32(buffer)
-	expectedBlockAlign := internal.ReadLittleEndianUInt16(buffer)
-	f.BitsPerSample = internal.ReadLittleEndianUInt16(buffer)
+	return parseFormat(ch.Data, binary.LittleEndian)
+}
+
+// parseFormat will parse the provided format chunk data,
+// which must be at least 16 bytes long. If the format is
+// invalid, an error is returned.
+func parseFormat(data []byte, order binary.ByteOrder) (Format, error) {
+	var f Format
+	if len(data) < LengthFormatChunk-internal.LengthChunkHeader {
+		return f, fmt.Errorf("%w: format chunk is invalid size (%d)", ErrBadWaveData, len(data))
+	}
+
+	var raw formatData
+	if err := encoding.UnmarshalOrder(data, &raw, order); err != nil {
+		return f, fmt.Errorf("%w: %s", ErrBadWaveData, err)
+	}
+	f.AudioFormat = raw.AudioFormat
+	f.Channels = raw.Channels
+	f.SampleRate = raw.SampleRate
+	f.BitsPerSample = raw.BitsPerSample
+	expectedBytesPerSecond := raw.BytesPerSecond
+	expectedBlockAlign := raw.BlockAlign
 
 	if expectedBytesPerSecond != uint32(f.BytesPerSecond()) {
 		return f, fmt.Errorf(
@@ -234,13 +246,7 @@ func (f Format) BytesPerSecond() uint32 {
 }
 
 func formatToBytes(f Format) [LengthFormatChunk]byte {
-	formatData := make([]byte, 0, LengthFormatChunk-internal.LengthChunkHeader)
-	formatData = append(formatData, internal.LittleEndianUInt16Bytes(uint16(f.AudioFormat))...)
-	formatData = append(formatData, internal.LittleEndianUInt16Bytes(f.Channels)...)
-	formatData = append(formatData, internal.LittleEndianUInt32Bytes(f.SampleRate)...)
-	formatData = append(formatData, internal.LittleEndianUInt32Bytes(uint32(f.BytesPerSecond()))...)
-	formatData = append(formatData, internal.LittleEndianUInt16Bytes(uint16(f.BlockAlign()))...)
-	formatData = append(formatData, internal.LittleEndianUInt16Bytes(uint16(f.BitsPerSample))...)
+	formatData := formatDataBytes(f, binary.LittleEndian)
 
 	formatBuffer := make([]byte, 0, LengthFormatChunk)
 	formatBuffer = append(formatBuffer, goriffa.FourCCFormat[:]...)
@@ -255,6 +261,35 @@ func formatToBytes(f Format) [LengthFormatChunk]byte {
 	return rawBytes
 }
 
+// formatData is the layout of the data of the format
+// chunk, see Format.
+type formatData struct {
+	AudioFormat    AudioFormat
+	Channels       uint16
+	SampleRate     uint32
+	BytesPerSecond uint32
+	BlockAlign     uint16
+	BitsPerSample  uint16
+}
+
+// formatDataBytes returns the data of the format chunk
+// describing the provided format.
+func formatDataBytes(f Format, order binary.ByteOrder) []byte {
+	data, err := encoding.MarshalOrder(formatData{
+		AudioFormat:    f.AudioFormat,
+		Channels:       f.Channels,
+		SampleRate:     f.SampleRate,
+		BytesPerSecond: f.BytesPerSecond(),
+		BlockAlign:     f.BlockAlign(),
+		BitsPerSample:  f.BitsPerSample,
+	}, order)
+	if err != nil {
+		internal.Panic(err)
+	}
+
+	return data
+}
+
 // Validate will return an error if the provided
 // format is invalid.
 func Validate(f Format) error {
@@ -0,0 +1,70 @@
+package writer
+
+import (
+	"encoding/binary"
+
+	"github.com/standoffvenus/goriffa/internal"
+)
+
+// Option configures a Writer created by New.
+type Option func(*Writer)
+
+// PromoteToRF64 allows the writer to write more than
+// 4 GB of data. The writer reserves a "JUNK" chunk after
+// the RIFF header; if the data turns out to be larger
+// than 4 GB, the header is rewritten to "RF64" and the
+// "JUNK" chunk is turned into a "ds64" chunk holding the
+// 64-bit sizes. Otherwise, plain RIFF data is written.
+//
+// Only the "data" chunk may itself grow larger than
+// 4 GB, as no room is reserved for the ds64 chunk's
+// table of other chunk sizes. The ds64 chunk's sample
+// count is left as 0.
+func PromoteToRF64() Option {
+	return func(w *Writer) {
+		w.promote = true
+	}
+}
+
+// ByteOrder sets the byte order of the size fields
+// written. If binary.BigEndian is provided, "RIFX" data
+// is written in place of "RIFF" data. Only
+// binary.LittleEndian (the default) and binary.BigEndian
+// are supported.
+//
+// RIFX data cannot be promoted to RF64 data, so
+// ByteOrder(binary.BigEndian) and PromoteToRF64 must not
+// be used together.
+func ByteOrder(order binary.ByteOrder) Option {
+	return func(w *Writer) {
+		w.order = order
+	}
+}
+
+// TempFileThreshold moves the RIFF data spooled by a
+// writer created by NewBuffered to a temporary file once
+// more than n bytes have been written, rather than holding
+// it all in memory. The file is removed when the writer is
+// closed. A threshold of 0 (the default) means the data is
+// always held in memory.
+func TempFileThreshold(n int64) Option {
+	return func(w *Writer) {
+		w.threshold = n
+	}
+}
+
+// Align makes the writer insert a "JUNK" chunk before every
+// chunk with the provided identifier as needed for the
+// chunk's data to begin at a multiple of alignment bytes
+// from the start of the stream - e.g. 4096 for the "data"
+// chunk of a Wavefile meant for direct I/O. For lists, the
+// data begins with the list type. The alignment must be
+// even, as chunks always begin at even offsets.
+func Align(identifier internal.FourCC, alignment int64) Option {
+	return func(w *Writer) {
+		if w.alignments == nil {
+			w.alignments = make(map[internal.FourCC]int64)
+		}
+		w.alignments[identifier] = alignment
+	}
+}
@@ -0,0 +1,149 @@
+package writer_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/internal"
+	"github.com/standoffvenus/goriffa/internal/test"
+	"github.com/standoffvenus/goriffa/wave"
+	"github.com/standoffvenus/goriffa/writer"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleWriteChunks() {
+	var buf bytes.Buffer // Any io.Writer, e.g. an HTTP response
+	if err := writer.WriteChunks(&buf, wave.FileTypeWavefile, []goriffa.Chunk{
+		{Identifier: goriffa.FourCCData, Data: []byte("Hello, world!")},
+	}); err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("Wrote %d bytes.", buf.Len())
+
+	// Output: Wrote 34 bytes.
+}
+
+func TestWriteChunks(t *testing.T) {
+	chunks := []goriffa.Chunk{
+		{Identifier: goriffa.FourCCFormat, Data: []byte{1, 2, 3}},
+		{Identifier: goriffa.FourCCData, Data: []byte{4, 5}},
+	}
+
+	var expected test.Buffer
+	w, err := writer.New(&expected, test.FileType)
+	assert.NoError(t, err)
+	for _, c := range chunks {
+		_, writeErr := w.WriteChunk(c)
+		assert.NoError(t, writeErr)
+	}
+	assert.NoError(t, w.Close())
+
+	var buf bytes.Buffer
+	assert.NoError(t, writer.WriteChunks(&buf, test.FileType, chunks))
+	assert.Equal(t, expected.Bytes(), buf.Bytes())
+}
+
+func TestNewSized(t *testing.T) {
+	name := goriffa.Chunk{Identifier: fourCC("INAM"), Data: []byte("odd")}
+	data := []byte("streamed")
+
+	var expected test.Buffer
+	w, err := writer.New(&expected, test.FileType)
+	assert.NoError(t, err)
+	info, infoErr := w.CreateList(internal.StringMust4Byte("INFO"))
+	assert.NoError(t, infoErr)
+	_, nameErr := info.WriteChunk(name)
+	assert.NoError(t, nameErr)
+	_, dataErr := w.WriteChunk(goriffa.Chunk{Identifier: goriffa.FourCCData, Data: data})
+	assert.NoError(t, dataErr)
+	assert.NoError(t, w.Close())
+
+	var buf bytes.Buffer
+	sized, err := writer.NewSized(&buf, test.FileType, int64(internal.LengthListHeader)+name.ByteLength()+int64(internal.LengthChunkHeader+len(data)))
+	assert.NoError(t, err)
+
+	// Sizes can't be back-patched.
+	_, listErr := sized.CreateList(internal.StringMust4Byte("INFO"))
+	assert.Error(t, listErr)
+	_, beginErr := sized.BeginChunk(goriffa.FourCCData)
+	assert.Error(t, beginErr)
+
+	sizedInfo, infoErr := sized.CreateSizedList(internal.StringMust4Byte("INFO"), name.ByteLength())
+	assert.NoError(t, infoErr)
+	_, nameErr = sizedInfo.WriteChunk(name)
+	assert.NoError(t, nameErr)
+
+	cw, beginErr := sized.BeginSizedChunk(goriffa.FourCCData, int64(len(data)))
+	assert.NoError(t, beginErr)
+	_, copyErr := io.Copy(cw, strings.NewReader(string(data)))
+	assert.NoError(t, copyErr)
+	assert.NoError(t, cw.Close())
+	assert.NoError(t, sized.Close())
+
+	assert.Equal(t, expected.Bytes(), buf.Bytes())
+}
+
+func TestNewSizedWriteTooMuch(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := writer.NewSized(&buf, test.FileType, 10)
+	assert.NoError(t, err)
+
+	_, writeErr := w.WriteChunk(goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{1, 2, 3}})
+	assert.ErrorIs(t, writeErr, goriffa.ErrCorrupted)
+}
+
+func TestNewSizedWriteTooLittle(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := writer.NewSized(&buf, test.FileType, 12)
+	assert.NoError(t, err)
+
+	_, writeErr := w.WriteChunk(goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{1}})
+	assert.NoError(t, writeErr)
+	assert.ErrorIs(t, w.Close(), goriffa.ErrCorrupted)
+}
+
+func TestNewSizedPromoteToRF64(t *testing.T) {
+	_, err := writer.NewSized(new(bytes.Buffer), test.FileType, 0, writer.PromoteToRF64())
+	assert.Error(t, err)
+}
+
+func TestBeginSizedChunkMismatch(t *testing.T) {
+	var buffer test.Buffer
+	w, err := writer.New(&buffer, test.FileType)
+	assert.NoError(t, err)
+
+	cw, beginErr := w.BeginSizedChunk(goriffa.FourCCData, 2)
+	assert.NoError(t, beginErr)
+	_, writeErr := cw.Write([]byte{1, 2, 3})
+	assert.ErrorIs(t, writeErr, goriffa.ErrCorrupted)
+	_, writeErr = cw.Write([]byte{1})
+	assert.NoError(t, writeErr)
+	assert.ErrorIs(t, cw.Close(), goriffa.ErrCorrupted)
+}
+
+func TestBeginSizedChunkBackPatchesParents(t *testing.T) {
+	var buffer test.Buffer
+	w, err := writer.New(&buffer, test.FileType)
+	assert.NoError(t, err)
+
+	cw, beginErr := w.BeginSizedChunk(goriffa.FourCCData, 3)
+	assert.NoError(t, beginErr)
+	_, writeErr := cw.Write([]byte{1, 2, 3})
+	assert.NoError(t, writeErr)
+	assert.NoError(t, cw.Close())
+
+	var expected bytes.Buffer
+	expected.Write(goriffa.FourCCRIFF[:])
+	expected.Write(internal.LittleEndianUInt32Bytes(4 + 12))
+	expected.Write(test.FileType[:])
+	expected.Write(goriffa.FourCCData[:])
+	expected.Write(internal.LittleEndianUInt32Bytes(3))
+	expected.Write([]byte{1, 2, 3, 0})
+
+	assert.Equal(t, expected.Bytes(), buffer.Bytes())
+}
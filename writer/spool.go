@@ -0,0 +1,99 @@
+package writer
+
+import (
+	"io"
+	"os"
+
+	"github.com/standoffvenus/goriffa/internal"
+)
+
+// spool holds the RIFF data written by a writer created by
+// NewBuffered until it's flushed to dst. The data is held
+// in memory until it grows beyond threshold (if set), after
+// which it's moved to a temporary file.
+type spool struct {
+	dst       io.Writer
+	threshold int64
+
+	buf  []byte
+	file *os.File
+	size int64
+}
+
+func (s *spool) Write(b []byte) (int, error) {
+	return s.WriteAt(b, s.size)
+}
+
+func (s *spool) WriteAt(b []byte, offset int64) (int, error) {
+	if s.file == nil && s.threshold > 0 && offset+int64(len(b)) > s.threshold {
+		if err := s.moveToFile(); err != nil {
+			return 0, err
+		}
+	}
+
+	var (
+		n   int
+		err error
+	)
+	if s.file != nil {
+		n, err = s.file.WriteAt(b, offset)
+	} else {
+		if end := offset + int64(len(b)); end > int64(len(s.buf)) {
+			s.buf = append(s.buf, make([]byte, end-int64(len(s.buf)))...)
+		}
+		n = copy(s.buf[offset:], b)
+	}
+
+	if end := offset + int64(n); end > s.size {
+		s.size = end
+	}
+
+	return n, err
+}
+
+// flush will copy the spooled data to dst, after which
+// the spool is discarded.
+func (s *spool) flush() error {
+	defer s.discard()
+
+	if s.file == nil {
+		_, err := internal.Write(s.dst, s.buf)
+
+		return err
+	}
+
+	_, err := io.Copy(s.dst, io.NewSectionReader(s.file, 0, s.size))
+
+	return err
+}
+
+// discard will release the spooled data, removing the
+// temporary file if there is one.
+func (s *spool) discard() {
+	s.buf = nil
+	if s.file != nil {
+		s.file.Close()
+		os.Remove(s.file.Name())
+		s.file = nil
+	}
+}
+
+// moveToFile will move the data held in memory to a new
+// temporary file.
+func (s *spool) moveToFile() error {
+	f, err := os.CreateTemp("", "goriffa-*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(s.buf); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+
+		return err
+	}
+
+	s.file = f
+	s.buf = nil
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package writer_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/standoffvenus/goriffa"
+	"github.com/standoffvenus/goriffa/internal"
+	"github.com/standoffvenus/goriffa/internal/test"
+	"github.com/standoffvenus/goriffa/writer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBuffered(t *testing.T) {
+	testBuffered(t)
+}
+
+func TestNewBufferedTempFile(t *testing.T) {
+	before, err := filepath.Glob(filepath.Join(os.TempDir(), "goriffa-*"))
+	assert.NoError(t, err)
+
+	testBuffered(t, writer.TempFileThreshold(16))
+
+	after, err := filepath.Glob(filepath.Join(os.TempDir(), "goriffa-*"))
+	assert.NoError(t, err)
+	assert.Equal(t, len(before), len(after), "temporary file should be removed")
+}
+
+func TestNewBufferedWriteError(t *testing.T) {
+	w, err := writer.NewBuffered(errWriter{}, test.FileType)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, w.Close(), io.ErrClosedPipe)
+}
+
+func TestNewBufferedShortWrite(t *testing.T) {
+	w, err := writer.NewBuffered(shortWriter{}, test.FileType)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, w.Close(), io.ErrShortWrite)
+}
+
+func testBuffered(t *testing.T, options ...writer.Option) {
+	name := goriffa.Chunk{Identifier: fourCC("INAM"), Data: []byte("odd")}
+	data := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: bytes.Repeat([]byte{7}, 33)}
+
+	var expected test.Buffer
+	w, err := writer.New(&expected, test.FileType)
+	assert.NoError(t, err)
+	writeTree(t, w, name, data)
+
+	var buf bytes.Buffer
+	buffered, err := writer.NewBuffered(&buf, test.FileType, options...)
+	assert.NoError(t, err)
+	writeTree(t, buffered, name, data)
+
+	assert.Equal(t, expected.Bytes(), buf.Bytes())
+}
+
+func writeTree(t *testing.T, w *writer.Writer, name, data goriffa.Chunk) {
+	info, err := w.CreateList(internal.StringMust4Byte("INFO"))
+	assert.NoError(t, err)
+	_, nameErr := info.WriteChunk(name)
+	assert.NoError(t, nameErr)
+
+	cw, beginErr := w.BeginChunk(data.Identifier)
+	assert.NoError(t, beginErr)
+	_, writeErr := cw.Write(data.Data)
+	assert.NoError(t, writeErr)
+
+	assert.NoError(t, w.Close())
+}
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+type shortWriter struct{}
+
+func (shortWriter) Write(b []byte) (int, error) {
+	return len(b) / 2, nil
+}
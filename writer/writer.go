@@ -3,6 +3,9 @@
 package writer
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -21,13 +24,48 @@ type WriterWithWriterAt interface {
 // Writer provides a mechanism for writing RIFF chunks
 // to an io.Writer.
 type Writer struct {
-	w WriterWithWriterAt
+	// at is nil if sizes can't be back-patched, i.e. for
+	// a Writer created by NewSized.
+	w  io.Writer
+	at io.WriterAt
 
 	fileType internal.FileType
 	fileSize int64
 	closed   bool
+
+	// offset holds the position of this writer's size
+	// field within w, which is back-patched on Close.
+	offset int64
+
+	// sized is set if this writer's size was declared
+	// up front, in which case its size field was written
+	// with the header and declared holds its value.
+	sized    bool
+	declared int64
+
+	// A Writer for a list writes through its parent; a
+	// parent closes its open list (or streamed chunk)
+	// before writing again.
+	parent *Writer
+	open   io.Closer
+
+	// order, promote, dataSize, spool, threshold and
+	// alignments are only used by the top-level writer,
+	// see ByteOrder, PromoteToRF64, NewBuffered and Align.
+	order      binary.ByteOrder
+	promote    bool
+	dataSize   uint64
+	spool      *spool
+	threshold  int64
+	alignments map[internal.FourCC]int64
 }
 
+var (
+	errUnsupportedByteOrder error = errors.New("unsupported byte order")
+	errUnknownSize          error = errors.New("size must be declared up front without io.WriterAt")
+	errInvalidAlignment     error = errors.New("invalid alignment")
+)
+
 var _ goriffa.Writer = new(Writer)
 
 // New creates a new RIFF writer, initially writing
@@ -40,19 +78,113 @@ var _ goriffa.Writer = new(Writer)
 // lead to corrupted data since writing the RIFF data
 // length is deferred until close.
 //
+// The writer may be configured with any number of
+// options, such as PromoteToRF64 or ByteOrder (with
+// which "RIFX" is written in place of "RIFF").
+//
 // The returned writer is NOT concurrent-safe.
-func New(w WriterWithWriterAt, fileType internal.FileType) (*Writer, error) {
-	writer := &Writer{
-		w:        w,
-		fileType: fileType,
+func New(w WriterWithWriterAt, fileType internal.FileType, options ...Option) (*Writer, error) {
+	writer, err := newWriter(w, w, fileType, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writer.init(); err != nil {
+		return nil, err
+	}
+
+	return writer, nil
+}
+
+// NewSized creates a new RIFF writer for a plain io.Writer
+// (e.g. a network connection or a pipe), which cannot be
+// back-patched. Instead, size is declared up front: the
+// total byte length of the chunks that will be written,
+// as given by Chunk.ByteLength. The RIFF header is written
+// with the size at once.
+//
+// As no size can be written later, lists must be created
+// via CreateSizedList and streamed chunks begun via
+// BeginSizedChunk. Writing more bytes than declared -
+// or closing the writer having written fewer - returns
+// goriffa.ErrCorrupted.
+//
+// PromoteToRF64 and Align are not supported by NewSized;
+// see NewBuffered instead.
+func NewSized(w io.Writer, fileType internal.FileType, size int64, options ...Option) (*Writer, error) {
+	writer, err := newWriter(w, nil, fileType, options)
+	if err != nil {
+		return nil, err
+	}
+	if writer.promote {
+		return nil, fmt.Errorf("%w: cannot promote data of a declared size to RF64", errUnknownSize)
+	}
+	if len(writer.alignments) > 0 {
+		return nil, fmt.Errorf("%w: cannot align chunks of data of a declared size", errUnknownSize)
+	}
+	if err := writer.declare(size + int64(len(fileType))); err != nil {
+		return nil, err
+	}
+
+	if err := writer.init(); err != nil {
+		return nil, err
+	}
+
+	return writer, nil
+}
+
+// NewBuffered creates a new RIFF writer for a plain
+// io.Writer which, unlike NewSized, requires no sizes up
+// front. The RIFF data is spooled - to memory or, see
+// TempFileThreshold, to a temporary file - where sizes
+// are back-patched as usual. The spooled data is flushed
+// to the provided writer on Close.
+//
+// The writer supports everything a writer created by New
+// supports, at the cost of holding the RIFF data until
+// it's closed.
+func NewBuffered(w io.Writer, fileType internal.FileType, options ...Option) (*Writer, error) {
+	s := &spool{dst: w}
+	writer, err := newWriter(s, s, fileType, options)
+	if err != nil {
+		return nil, err
 	}
+	s.threshold = writer.threshold
+	writer.spool = s
+
 	if err := writer.init(); err != nil {
+		s.discard()
+
 		return nil, err
 	}
 
 	return writer, nil
 }
 
+// WriteChunks will write RIFF data holding the provided
+// chunks to the provided writer, which - unlike New -
+// needn't support io.WriterAt: as all chunks are known
+// up front, the RIFF size is computed before the header
+// is written.
+func WriteChunks(w io.Writer, fileType internal.FileType, chunks []internal.Chunk, options ...Option) error {
+	var size int64
+	for _, c := range chunks {
+		size += c.ByteLength()
+	}
+
+	writer, err := NewSized(w, fileType, size, options...)
+	if err != nil {
+		return err
+	}
+	for _, c := range chunks {
+		if _, err := writer.WriteChunk(c); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
 // WriteChunk will write the given chunk to the stream
 // in the RIFF format:
 //   identifier, size, data...
@@ -66,67 +198,575 @@ func New(w WriterWithWriterAt, fileType internal.FileType) (*Writer, error) {
 //  (goriffa.LengthChunkHeader + len(c.Data)) + 1 (if padding byte added)
 //
 // If the writer has written too many bytes (more than
-// 4 GB), goriffa.ErrCorrupted will be returned.
+// 4 GB, unless PromoteToRF64 is used), goriffa.ErrCorrupted
+// will be returned.
 // If the writer is closed, goriffa.ErrClosed will be
 // returned.
+//
+// If a list created via CreateList (or a chunk begun via
+// BeginChunk) is still open, it is closed before the chunk
+// is written.
 func (w *Writer) WriteChunk(c internal.Chunk) (int, error) {
 	if !w.closed {
-		// This is an overflow check
-		newSize := w.fileSize + c.ByteLength()
-		if newSize > math.MaxUint32 || newSize < w.fileSize {
-			return 0, fmt.Errorf("%w: wrote too many bytes - size overflow", internal.ErrCorrupted)
+		if err := w.closeOpen(); err != nil {
+			return 0, err
+		}
+		if err := w.align(c.Identifier); err != nil {
+			return 0, err
+		}
+		if err := w.grow(c.ByteLength()); err != nil {
+			return 0, err
+		}
+
+		sizeBytes, sizeErr := w.chunkSizeBytes(c.Identifier, int64(len(c.Data)))
+		if sizeErr != nil {
+			return 0, sizeErr
 		}
 
 		b := internal.Pad(c.Data)
-		n, err := internal.Write(
-			w.w,
+		n, err := w.write(
 			c.Identifier[:],
-			internal.LittleEndianUInt32Bytes(uint32(len(c.Data))),
+			sizeBytes,
 			b)
-		if err != nil {
-			return int(n), err
+
+		return int(n), err
+	}
+
+	return 0, internal.ErrClosed
+}
+
+// CreateList will write the header of a new list chunk
+//   "LIST", [4 empty bytes for list size], listType
+// to the stream and return a Writer for the list's
+// sub-chunks. Chunks written to the returned Writer are
+// written to the stream at once, but the list's size
+// is back-patched via io.WriterAt when the list Writer
+// is closed. Lists may be nested to any depth.
+//
+// Writing to this writer (or closing it) closes the
+// list first, after which any use of the list Writer
+// returns goriffa.ErrClosed.
+//
+// If the writer is closed, goriffa.ErrClosed will be
+// returned. For a writer created by NewSized, use
+// CreateSizedList instead.
+func (w *Writer) CreateList(listType internal.FileType) (*Writer, error) {
+	if w.at == nil {
+		return nil, fmt.Errorf("%w: use CreateSizedList", errUnknownSize)
+	}
+
+	return w.createList(listType, -1)
+}
+
+// CreateSizedList works like CreateList, except that the
+// list's size is written with its header: size is the
+// total byte length of the sub-chunks that will be
+// written to the list, as given by Chunk.ByteLength.
+// Writing more bytes than declared - or closing the list
+// having written fewer - returns goriffa.ErrCorrupted.
+func (w *Writer) CreateSizedList(listType internal.FileType, size int64) (*Writer, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("%w: negative list size (%d)", internal.ErrCorrupted, size)
+	}
+
+	return w.createList(listType, size+int64(len(listType)))
+}
+
+// createList will create a list of the provided type. If
+// size isn't negative, the list's size field is written
+// with its header.
+func (w *Writer) createList(listType internal.FileType, size int64) (*Writer, error) {
+	if w.closed {
+		return nil, internal.ErrClosed
+	}
+	if err := w.closeOpen(); err != nil {
+		return nil, err
+	}
+	if err := w.align(goriffa.FourCCList); err != nil {
+		return nil, err
+	}
+	if err := w.grow(int64(internal.LengthListHeader)); err != nil {
+		return nil, err
+	}
+
+	list := &Writer{
+		w:        w.w,
+		at:       w.at,
+		fileType: listType,
+		offset:   w.position() + int64(len(goriffa.FourCCList)),
+		parent:   w,
+	}
+
+	sizeBytes := internal.EmptyBytes[:]
+	if size >= 0 {
+		if err := list.declare(size); err != nil {
+			return nil, err
 		}
+		sizeBytes = internal.UInt32Bytes(w.root().order, uint32(size))
+	}
+
+	if _, err := w.write(
+		goriffa.FourCCList[:],
+		sizeBytes,
+		listType[:],
+	); err != nil {
+		return nil, err
+	}
+	list.fileSize = int64(len(listType))
+	w.open = list
 
-		w.fileSize += n
+	return list, nil
+}
 
-		return int(n), nil
+// BeginChunk will write the header of a new chunk
+//   identifier, [4 empty bytes for chunk size]
+// to the stream and return a writer for the chunk's data,
+// allowing the data to be streamed rather than held in
+// memory entirely. Data written to the returned writer is
+// written to the stream at once. When the returned writer
+// is closed, the padding byte (if needed) is written and
+// the chunk's size - as well as the size of this writer
+// and its parents - is back-patched via io.WriterAt.
+//
+// Writing to this writer (or closing it) closes the
+// chunk first, after which any use of the returned
+// writer returns goriffa.ErrClosed.
+//
+// If the writer is closed, goriffa.ErrClosed will be
+// returned. For a writer created by NewSized, use
+// BeginSizedChunk instead.
+func (w *Writer) BeginChunk(identifier internal.FourCC) (io.WriteCloser, error) {
+	if w.at == nil {
+		return nil, fmt.Errorf("%w: use BeginSizedChunk", errUnknownSize)
 	}
 
-	return 0, internal.ErrClosed
+	return w.beginChunk(identifier, -1)
+}
+
+// BeginSizedChunk works like BeginChunk, except that the
+// chunk's size is written with its header, so nothing is
+// back-patched when the returned writer is closed (other
+// than the sizes of enclosing writers not created by
+// NewSized or CreateSizedList). Writing more than size
+// bytes - or closing the returned writer having written
+// fewer - returns goriffa.ErrCorrupted.
+func (w *Writer) BeginSizedChunk(identifier internal.FourCC, size int64) (io.WriteCloser, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("%w: negative chunk size (%d)", internal.ErrCorrupted, size)
+	}
+
+	return w.beginChunk(identifier, size)
+}
+
+// beginChunk will begin a chunk with the provided
+// identifier. If size isn't negative, the chunk's size
+// field is written with its header.
+func (w *Writer) beginChunk(identifier internal.FourCC, size int64) (io.WriteCloser, error) {
+	if w.closed {
+		return nil, internal.ErrClosed
+	}
+	if err := w.closeOpen(); err != nil {
+		return nil, err
+	}
+	if err := w.align(identifier); err != nil {
+		return nil, err
+	}
+
+	headerSize := int64(internal.LengthChunkHeader)
+	if size >= 0 {
+		headerSize += internal.PaddedLength(size)
+	}
+	if err := w.grow(headerSize); err != nil {
+		return nil, err
+	}
+
+	cw := &chunkWriter{
+		w:          w,
+		identifier: identifier,
+		offset:     w.position() + int64(len(identifier)),
+		declared:   size,
+	}
+
+	sizeBytes := internal.EmptyBytes[:]
+	if size >= 0 {
+		var sizeErr error
+		if sizeBytes, sizeErr = w.chunkSizeBytes(identifier, size); sizeErr != nil {
+			return nil, sizeErr
+		}
+	}
+
+	if _, err := w.write(identifier[:], sizeBytes); err != nil {
+		return nil, err
+	}
+	w.open = cw
+
+	return cw, nil
 }
 
 // Close will close the writer, writing the content
-// length at the file size offset.
+// length at the file size offset. For a Writer returned
+// by CreateList, the list's size is written instead.
+// Any open list is closed first.
 // All seeks and writes after close will fail.
 // If the write fails, the write error will be
 // returned.
 //
+// For a writer created by NewBuffered, the spooled RIFF
+// data is flushed to the underlying writer.
+//
 // If the writer is already closed, goriffa.ErrClosed
 // will be returned.
 func (w *Writer) Close() error {
 	if !w.closed {
 		w.closed = true
+		if w.parent != nil {
+			w.parent.open = nil
+		}
 
-		fileSizeBytes := internal.LittleEndianUInt32Bytes(uint32(w.fileSize))
-		if _, err := internal.WriteAt(w.w, fileSizeBytes[:], int64(len(goriffa.FourCCRIFF))); err != nil {
+		err := w.closeOpen()
+		if err == nil {
+			err = w.writeSize()
+		}
+		if w.spool == nil {
 			return err
 		}
+		if err != nil {
+			w.spool.discard()
 
-		return nil
+			return err
+		}
+
+		return w.spool.flush()
 	}
 
 	return internal.ErrClosed
 }
 
+// newWriter will create a top-level writer configured
+// with the provided options, without writing anything.
+func newWriter(w io.Writer, at io.WriterAt, fileType internal.FileType, options []Option) (*Writer, error) {
+	writer := &Writer{
+		w:        w,
+		at:       at,
+		fileType: fileType,
+		offset:   int64(len(goriffa.FourCCRIFF)),
+		order:    binary.LittleEndian,
+	}
+	for _, option := range options {
+		option(writer)
+	}
+
+	switch writer.order {
+	case binary.LittleEndian:
+	case binary.BigEndian:
+		if writer.promote {
+			return nil, fmt.Errorf("%w: RF64 data must be little-endian", errUnsupportedByteOrder)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %v", errUnsupportedByteOrder, writer.order)
+	}
+
+	for identifier, alignment := range writer.alignments {
+		if alignment <= 0 || alignment%2 != 0 || alignment > math.MaxUint32 {
+			return nil, fmt.Errorf("%w: %d for %q (must be even)", errInvalidAlignment, alignment, identifier)
+		}
+	}
+
+	return writer, nil
+}
+
 func (w *Writer) init() error {
+	prefix := goriffa.FourCCRIFF
+	if w.order == binary.BigEndian {
+		prefix = goriffa.FourCCRIFX
+	}
+
+	sizeBytes := internal.EmptyBytes[:]
+	if w.sized {
+		sizeBytes = internal.UInt32Bytes(w.order, uint32(w.declared))
+	}
+
 	if _, err := internal.Write(w.w,
-		goriffa.FourCCRIFF[:],
-		internal.EmptyBytes[:],
+		prefix[:],
+		sizeBytes,
 		w.fileType[:],
 	); err != nil {
 		return err
 	}
 	w.fileSize = 4 // Because we wrote the file type
 
+	if w.promote {
+		// Reserve room for the ds64 chunk.
+		if _, err := w.WriteChunk(internal.Chunk{
+			Identifier: goriffa.FourCCJunk,
+			Data:       make([]byte, internal.LengthDS64),
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// align will write a "JUNK" chunk if needed for the data
+// of the next chunk - identified by the provided identifier
+// - to begin at the alignment set via Align.
+func (w *Writer) align(identifier internal.FourCC) error {
+	alignment := w.root().alignments[identifier]
+	if alignment == 0 {
+		return nil
+	}
+
+	dataOffset := w.position() + int64(internal.LengthChunkHeader)
+	padding := (alignment - dataOffset%alignment) % alignment
+	for padding != 0 && padding < int64(internal.LengthChunkHeader) {
+		padding += alignment
+	}
+	if padding == 0 {
+		return nil
+	}
+
+	if err := w.grow(padding); err != nil {
+		return err
+	}
+
+	size := padding - int64(internal.LengthChunkHeader)
+	_, err := w.write(
+		goriffa.FourCCJunk[:],
+		internal.UInt32Bytes(w.root().order, uint32(size)),
+		make([]byte, size))
+
+	return err
+}
+
+// closeOpen will close the list created by CreateList
+// or the chunk begun by BeginChunk, if it's still open.
+func (w *Writer) closeOpen() error {
+	if w.open != nil {
+		return w.open.Close()
+	}
+
+	return nil
+}
+
+// declare will mark this writer's size as declared up
+// front, with the provided value of its size field.
+func (w *Writer) declare(size int64) error {
+	if size < 0 || size > math.MaxUint32 {
+		return fmt.Errorf("%w: declared size (%d) out of range", internal.ErrCorrupted, size)
+	}
+	w.sized = true
+	w.declared = size
+
+	return nil
+}
+
+// writeSize will write the current size of this writer
+// at its size field's offset. If the size was declared up
+// front, it is only checked against the bytes written.
+func (w *Writer) writeSize() error {
+	if w.sized {
+		if w.fileSize != w.declared {
+			return fmt.Errorf("%w: wrote %d bytes but declared %d", internal.ErrCorrupted, w.fileSize, w.declared)
+		}
+
+		return nil
+	}
+
+	if w.fileSize > math.MaxUint32 {
+		return w.writeRF64Header()
+	}
+
+	fileSizeBytes := internal.UInt32Bytes(w.root().order, uint32(w.fileSize))
+	if _, err := internal.WriteAt(w.at, fileSizeBytes[:], w.offset); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// writeRF64Header will rewrite the RIFF header as an RF64
+// header and the reserved "JUNK" chunk as a "ds64" chunk
+// holding the 64-bit sizes.
+func (w *Writer) writeRF64Header() error {
+	ds64 := internal.DS64{
+		RIFFSize: uint64(w.fileSize),
+		DataSize: w.dataSize,
+	}
+
+	var header bytes.Buffer
+	header.Write(goriffa.FourCCRF64[:])
+	header.Write(internal.LittleEndianUInt32Bytes(math.MaxUint32))
+	header.Write(w.fileType[:])
+	header.Write(goriffa.FourCCDS64[:])
+	header.Write(internal.LittleEndianUInt32Bytes(uint32(internal.LengthDS64)))
+	header.Write(ds64.Bytes())
+
+	_, err := internal.WriteAt(w.at, header.Bytes(), 0)
+
+	return err
+}
+
+// chunkSizeBytes will return the size field of a chunk
+// with the provided identifier and size. The size field
+// of a "data" chunk larger than 4 GB is 0xFFFFFFFF, with
+// its size held by the ds64 chunk instead.
+func (w *Writer) chunkSizeBytes(identifier internal.FourCC, size int64) ([]byte, error) {
+	root := w.root()
+	isData := root.promote && identifier == goriffa.FourCCData
+	if isData {
+		root.dataSize = uint64(size)
+	}
+
+	if size <= math.MaxUint32 {
+		return internal.UInt32Bytes(root.order, uint32(size)), nil
+	}
+	if !isData {
+		return nil, fmt.Errorf("%w: chunk too large - size overflow", internal.ErrCorrupted)
+	}
+
+	return internal.LittleEndianUInt32Bytes(math.MaxUint32), nil
+}
+
+// grow will return an error if writing n more bytes would
+// overflow the size field of this writer or any of its
+// parents.
+func (w *Writer) grow(n int64) error {
+	for writer := w; writer != nil; writer = writer.parent {
+		maxSize := int64(math.MaxUint32)
+		if writer.sized {
+			maxSize = writer.declared
+		} else if writer.parent == nil && writer.promote {
+			maxSize = math.MaxInt64
+		}
+
+		// This is an overflow check
+		newSize := writer.fileSize + n
+		if newSize > maxSize || newSize < writer.fileSize {
+			return fmt.Errorf("%w: wrote too many bytes - size overflow", internal.ErrCorrupted)
+		}
+	}
+
+	return nil
+}
+
+// write will write the content to the stream, counting the
+// bytes written towards this writer and all of its parents.
+func (w *Writer) write(content ...[]byte) (int64, error) {
+	var (
+		n   int64
+		err error
+	)
+	if w.parent != nil {
+		n, err = w.parent.write(content...)
+	} else {
+		n, err = internal.Write(w.w, content...)
+	}
+	w.fileSize += n
+
+	return n, err
+}
+
+// root returns the top-level writer.
+func (w *Writer) root() *Writer {
+	for w.parent != nil {
+		w = w.parent
+	}
+
+	return w
+}
+
+// position returns the offset within the stream at which
+// the next byte will be written.
+func (w *Writer) position() int64 {
+	return w.offset + 4 + w.fileSize
+}
+
+// chunkWriter writes the data of a single chunk, as
+// returned by BeginChunk.
+type chunkWriter struct {
+	w          *Writer
+	identifier internal.FourCC
+
+	// offset holds the position of the chunk's size
+	// field within the stream, whereas declared holds
+	// the size passed to BeginSizedChunk (or -1).
+	offset   int64
+	size     int64
+	declared int64
+	closed   bool
+}
+
+func (cw *chunkWriter) Write(b []byte) (int, error) {
+	if cw.closed {
+		return 0, internal.ErrClosed
+	}
+	if cw.declared >= 0 {
+		if cw.size+int64(len(b)) > cw.declared {
+			return 0, fmt.Errorf("%w: wrote more than the declared chunk size (%d)", internal.ErrCorrupted, cw.declared)
+		}
+	} else if err := cw.w.grow(int64(len(b))); err != nil {
+		return 0, err
+	}
+	if newSize := cw.size + int64(len(b)); newSize > math.MaxUint32 &&
+		(!cw.w.root().promote || cw.identifier != goriffa.FourCCData) {
+		return 0, fmt.Errorf("%w: chunk too large - size overflow", internal.ErrCorrupted)
+	}
+
+	n, err := cw.w.write(b)
+	cw.size += n
+
+	return int(n), err
+}
+
+func (cw *chunkWriter) Close() error {
+	if cw.closed {
+		return internal.ErrClosed
+	}
+	cw.closed = true
+	cw.w.open = nil
+
+	if cw.declared >= 0 && cw.size != cw.declared {
+		return fmt.Errorf("%w: wrote %d bytes but declared %d", internal.ErrCorrupted, cw.size, cw.declared)
+	}
+
+	if padding := internal.PaddedLength(cw.size) - cw.size; padding > 0 {
+		// Room for the padding of a sized chunk was
+		// reserved when it began.
+		if cw.declared < 0 {
+			if err := cw.w.grow(padding); err != nil {
+				return err
+			}
+		}
+		if _, err := cw.w.write(make([]byte, padding)); err != nil {
+			return err
+		}
+	}
+
+	if cw.declared < 0 {
+		sizeBytes, sizeErr := cw.w.chunkSizeBytes(cw.identifier, cw.size)
+		if sizeErr != nil {
+			return sizeErr
+		}
+		if _, err := internal.WriteAt(cw.w.at, sizeBytes, cw.offset); err != nil {
+			return err
+		}
+	}
+	if cw.w.at == nil {
+		return nil
+	}
+
+	// Keep the sizes of the enclosing lists and the RIFF
+	// data up to date, so the stream is valid even if the
+	// writer is never closed (e.g. a crash while recording).
+	for writer := cw.w; writer != nil; writer = writer.parent {
+		if writer.sized {
+			continue
+		}
+		if err := writer.writeSize(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
@@ -2,7 +2,9 @@ package writer_test
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/standoffvenus/goriffa"
 	"github.com/standoffvenus/goriffa/internal"
@@ -36,6 +38,41 @@ func Example() {
 	// Output: Wrote 22 bytes.
 }
 
+func ExampleWriter_BeginChunk() {
+	f, close, err := OpenTemp()
+	if err != nil {
+		panic(err)
+	}
+	defer close()
+
+	riffWriter, writerErr := writer.New(f, wave.FileTypeWavefile)
+	if writerErr != nil {
+		panic(writerErr)
+	}
+	defer riffWriter.Close()
+
+	data, beginErr := riffWriter.BeginChunk(goriffa.FourCCData)
+	if beginErr != nil {
+		panic(beginErr)
+	}
+
+	// Stream the data from anywhere - e.g. an audio
+	// input - without knowing its size up front.
+	n, copyErr := io.Copy(data, strings.NewReader("Hello, world!"))
+	if copyErr != nil {
+		panic(copyErr)
+	}
+
+	// Closing the chunk writes its size.
+	if err := data.Close(); err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("Streamed %d bytes.", n)
+
+	// Output: Streamed 13 bytes.
+}
+
 func OpenTemp() (*os.File, func() error, error) {
 	f, err := os.CreateTemp(os.TempDir(), "")
 	if err != nil {
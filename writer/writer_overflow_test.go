@@ -30,3 +30,74 @@ func TestWriteOverflow(t *testing.T) {
 	assert.Equal(t, int(0), n)
 	assert.ErrorIs(t, err, goriffa.ErrCorrupted)
 }
+
+func TestWritePromoteToRF64(t *testing.T) {
+	var buf test.Buffer
+	w, err := New(&buf, test.FileType, PromoteToRF64())
+	assert.NoError(t, err)
+
+	// Set the file size super high.
+	w.fileSize = math.MaxUint32
+
+	data := internal.Chunk{Identifier: goriffa.FourCCData, Data: []byte{1, 2}}
+	_, writeErr := w.WriteChunk(data)
+	assert.NoError(t, writeErr)
+	assert.NoError(t, w.Close())
+
+	expectedDS64 := internal.DS64{
+		RIFFSize: math.MaxUint32 + uint64(data.ByteLength()),
+		DataSize: uint64(len(data.Data)),
+	}
+
+	actual := buf.Bytes()
+	assert.Equal(t, goriffa.FourCCRF64[:], actual[:4])
+	assert.Equal(t, internal.LittleEndianUInt32Bytes(math.MaxUint32), actual[4:8])
+	assert.Equal(t, test.FileType[:], actual[8:12])
+	assert.Equal(t, goriffa.FourCCDS64[:], actual[12:16])
+	assert.Equal(t, internal.LittleEndianUInt32Bytes(uint32(internal.LengthDS64)), actual[16:20])
+	assert.Equal(t, expectedDS64.Bytes(), actual[20:20+internal.LengthDS64])
+}
+
+func TestBeginChunkPromoteToRF64(t *testing.T) {
+	var buf test.Buffer
+	w, err := New(&buf, test.FileType, PromoteToRF64())
+	assert.NoError(t, err)
+
+	cw, beginErr := w.BeginChunk(goriffa.FourCCData)
+	assert.NoError(t, beginErr)
+
+	// Emulate streaming 4 GB of data.
+	cw.(*chunkWriter).size = math.MaxUint32
+	w.fileSize += math.MaxUint32
+
+	_, writeErr := cw.Write([]byte{1, 2})
+	assert.NoError(t, writeErr)
+	assert.NoError(t, cw.Close())
+
+	actual := buf.Bytes()
+	ds64, parseErr := internal.ParseDS64(actual[20 : 20+internal.LengthDS64])
+	assert.NoError(t, parseErr)
+	assert.Equal(t, uint64(math.MaxUint32+2), ds64.DataSize)
+	assert.Equal(t, uint64(w.fileSize), ds64.RIFFSize)
+
+	dataOffset := 12 + internal.LengthChunkHeader + internal.LengthDS64
+	assert.Equal(t, goriffa.FourCCData[:], actual[dataOffset:dataOffset+4])
+	assert.Equal(t, internal.LittleEndianUInt32Bytes(math.MaxUint32), actual[dataOffset+4:dataOffset+8])
+}
+
+func TestBeginChunkOverflow(t *testing.T) {
+	var buf test.Buffer
+	w, err := New(&buf, test.FileType, PromoteToRF64())
+	assert.NoError(t, err)
+
+	cw, beginErr := w.BeginChunk(goriffa.FourCCSMPL)
+	assert.NoError(t, beginErr)
+
+	// Only the data chunk may grow larger than 4 GB.
+	cw.(*chunkWriter).size = math.MaxUint32
+	w.fileSize += math.MaxUint32
+
+	n, writeErr := cw.Write([]byte{1})
+	assert.Equal(t, int(0), n)
+	assert.ErrorIs(t, writeErr, goriffa.ErrCorrupted)
+}
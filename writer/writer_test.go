@@ -2,12 +2,14 @@ package writer_test
 
 import (
 	"bytes"
+	"encoding/binary"
 	"errors"
 	"testing"
 
 	"github.com/standoffvenus/goriffa"
 	"github.com/standoffvenus/goriffa/internal"
 	"github.com/standoffvenus/goriffa/internal/test"
+	"github.com/standoffvenus/goriffa/reader"
 	"github.com/standoffvenus/goriffa/writer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -124,6 +126,283 @@ func TestWrite(t *testing.T) {
 	assert.True(t, bytes.Equal(expectedStream, actualContents))
 }
 
+func TestBeginChunk(t *testing.T) {
+	chunk := goriffa.Chunk{
+		Identifier: goriffa.FourCCData,
+		Data:       []byte("streamed data"),
+	}
+
+	var expected test.Buffer
+	expectedWriter, err := writer.New(&expected, test.FileType)
+	assert.NoError(t, err)
+	_, writeErr := expectedWriter.WriteChunk(chunk)
+	assert.NoError(t, writeErr)
+	assert.NoError(t, expectedWriter.Close())
+
+	var buffer test.Buffer
+	w, err := writer.New(&buffer, test.FileType)
+	assert.NoError(t, err)
+
+	cw, beginErr := w.BeginChunk(chunk.Identifier)
+	assert.NoError(t, beginErr)
+	for _, part := range [][]byte{chunk.Data[:5], chunk.Data[5:]} {
+		n, partErr := cw.Write(part)
+		assert.NoError(t, partErr)
+		assert.Equal(t, len(part), n)
+	}
+	assert.NoError(t, cw.Close())
+
+	// The chunk and RIFF sizes are patched as soon as the
+	// chunk is closed.
+	assert.Equal(t, expected.Bytes(), buffer.Bytes())
+	assert.NoError(t, w.Close())
+	assert.Equal(t, expected.Bytes(), buffer.Bytes())
+
+	_, closedErr := cw.Write(chunk.Data)
+	assert.ErrorIs(t, closedErr, goriffa.ErrClosed)
+	assert.ErrorIs(t, cw.Close(), goriffa.ErrClosed)
+}
+
+func TestBeginChunkClosedByWriteChunk(t *testing.T) {
+	first := goriffa.Chunk{Identifier: goriffa.FourCCFormat, Data: []byte{1}}
+	second := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{2, 3}}
+
+	var expected test.Buffer
+	expectedWriter, err := writer.New(&expected, test.FileType)
+	assert.NoError(t, err)
+	for _, c := range []goriffa.Chunk{first, second} {
+		_, writeErr := expectedWriter.WriteChunk(c)
+		assert.NoError(t, writeErr)
+	}
+	assert.NoError(t, expectedWriter.Close())
+
+	var buffer test.Buffer
+	w, err := writer.New(&buffer, test.FileType)
+	assert.NoError(t, err)
+
+	list, listErr := w.CreateList(internal.StringMust4Byte("INFO"))
+	assert.NoError(t, listErr)
+	assert.NoError(t, list.Close())
+
+	cw, beginErr := w.BeginChunk(first.Identifier)
+	assert.NoError(t, beginErr)
+	_, streamErr := cw.Write(first.Data)
+	assert.NoError(t, streamErr)
+
+	_, writeErr := w.WriteChunk(second)
+	assert.NoError(t, writeErr)
+	assert.NoError(t, w.Close())
+
+	// Skip past the empty list, which the expected
+	// stream lacks.
+	actual := buffer.Bytes()
+	assert.Equal(t, expected.Bytes()[12:], actual[12+internal.LengthListHeader:])
+	assert.ErrorIs(t, cw.Close(), goriffa.ErrClosed)
+}
+
+func TestBeginChunkClosed(t *testing.T) {
+	var buffer test.Buffer
+	w, err := writer.New(&buffer, test.FileType)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	_, beginErr := w.BeginChunk(goriffa.FourCCData)
+	assert.ErrorIs(t, beginErr, goriffa.ErrClosed)
+}
+
+func TestNewPromoteToRF64(t *testing.T) {
+	chunk := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{1, 2, 3}}
+
+	var buffer test.Buffer
+	w, err := writer.New(&buffer, test.FileType, writer.PromoteToRF64())
+	assert.NoError(t, err)
+	_, writeErr := w.WriteChunk(chunk)
+	assert.NoError(t, writeErr)
+	assert.NoError(t, w.Close())
+
+	// Small enough data remains plain RIFF data, with the
+	// space reserved for the ds64 chunk left as JUNK.
+	r, readerErr := reader.New(bytes.NewReader(buffer.Bytes()))
+	assert.NoError(t, readerErr)
+	assert.Equal(t, goriffa.FourCCRIFF, r.Identifier())
+
+	chunks, readErr := r.ReadToEnd()
+	assert.NoError(t, readErr)
+	assert.Equal(t, []goriffa.Chunk{
+		{Identifier: goriffa.FourCCJunk, Data: make([]byte, internal.LengthDS64)},
+		chunk,
+	}, chunks)
+}
+
+func TestNewBigEndian(t *testing.T) {
+	chunk := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{1, 2, 3}}
+
+	var buffer test.Buffer
+	w, err := writer.New(&buffer, test.FileType, writer.ByteOrder(binary.BigEndian))
+	assert.NoError(t, err)
+	list, listErr := w.CreateList(internal.StringMust4Byte("INFO"))
+	assert.NoError(t, listErr)
+	_, writeErr := list.WriteChunk(chunk)
+	assert.NoError(t, writeErr)
+	assert.NoError(t, w.Close())
+
+	actual := buffer.Bytes()
+	assert.Equal(t, goriffa.FourCCRIFX[:], actual[:4])
+	assert.Equal(t, []byte{0, 0, 0, 4 + 12 + 12}, actual[4:8])
+	assert.Equal(t, []byte{0, 0, 0, 4 + 12}, actual[16:20])
+	assert.Equal(t, []byte{0, 0, 0, 3}, actual[28:32])
+
+	r, readerErr := reader.New(bytes.NewReader(actual))
+	assert.NoError(t, readerErr)
+	assert.Equal(t, binary.BigEndian, r.ByteOrder())
+
+	listReader, listReadErr := r.ReadList()
+	assert.NoError(t, listReadErr)
+
+	chunks, readErr := listReader.ReadToEnd()
+	assert.NoError(t, readErr)
+	assert.Equal(t, []goriffa.Chunk{chunk}, chunks)
+}
+
+func TestNewBigEndianRF64(t *testing.T) {
+	var buffer test.Buffer
+	_, err := writer.New(&buffer, test.FileType,
+		writer.ByteOrder(binary.BigEndian),
+		writer.PromoteToRF64())
+	assert.Error(t, err)
+	assert.Zero(t, buffer.Len())
+}
+
+func TestAlign(t *testing.T) {
+	var buffer test.Buffer
+	w, err := writer.New(&buffer, test.FileType, writer.Align(goriffa.FourCCData, 16))
+	assert.NoError(t, err)
+
+	// The data would begin at offset 42, only 6 bytes shy
+	// of alignment - too few to fit a "JUNK" chunk.
+	_, formatErr := w.WriteChunk(goriffa.Chunk{Identifier: goriffa.FourCCFormat, Data: make([]byte, 14)})
+	assert.NoError(t, formatErr)
+	_, dataErr := w.WriteChunk(goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{1, 2}})
+	assert.NoError(t, dataErr)
+	assert.NoError(t, w.Close())
+
+	idx, err := reader.NewIndex(bytes.NewReader(buffer.Bytes()))
+	assert.NoError(t, err)
+
+	entries := idx.Entries()
+	assert.Len(t, entries, 3)
+	assert.Equal(t, goriffa.FourCCJunk, entries[1].Identifier)
+	assert.Equal(t, int64(14), entries[1].Size)
+	assert.Equal(t, goriffa.FourCCData, entries[2].Identifier)
+	assert.Equal(t, int64(64), entries[2].Offset+int64(internal.LengthChunkHeader))
+}
+
+func TestAlignAlreadyAligned(t *testing.T) {
+	var buffer test.Buffer
+	w, err := writer.New(&buffer, test.FileType, writer.Align(goriffa.FourCCList, 4))
+	assert.NoError(t, err)
+
+	_, listErr := w.CreateList(internal.StringMust4Byte("INFO"))
+	assert.NoError(t, listErr)
+	assert.NoError(t, w.Close())
+
+	assert.Equal(t, 24, buffer.Len())
+}
+
+func TestAlignInvalid(t *testing.T) {
+	_, err := writer.New(new(test.Buffer), test.FileType, writer.Align(goriffa.FourCCData, 3))
+	assert.Error(t, err)
+
+	_, err = writer.NewSized(new(bytes.Buffer), test.FileType, 0, writer.Align(goriffa.FourCCData, 4096))
+	assert.Error(t, err)
+}
+
+func TestCreateList(t *testing.T) {
+	name := goriffa.Chunk{Identifier: fourCC("INAM"), Data: []byte("odd")}
+	strf := goriffa.Chunk{Identifier: fourCC("strf"), Data: []byte{1, 2}}
+	data := goriffa.Chunk{Identifier: goriffa.FourCCData, Data: []byte{3, 4}}
+
+	var buffer test.Buffer
+	w, err := writer.New(&buffer, test.FileType)
+	assert.NoError(t, err)
+
+	info, infoErr := w.CreateList(internal.StringMust4Byte("INFO"))
+	assert.NoError(t, infoErr)
+	_, nameErr := info.WriteChunk(name)
+	assert.NoError(t, nameErr)
+	assert.NoError(t, info.Close())
+
+	hdrl, hdrlErr := w.CreateList(internal.StringMust4Byte("hdrl"))
+	assert.NoError(t, hdrlErr)
+	strl, strlErr := hdrl.CreateList(internal.StringMust4Byte("strl"))
+	assert.NoError(t, strlErr)
+	_, strfErr := strl.WriteChunk(strf)
+	assert.NoError(t, strfErr)
+
+	// Writing to the parent closes the nested lists.
+	_, dataErr := w.WriteChunk(data)
+	assert.NoError(t, dataErr)
+	assert.NoError(t, w.Close())
+
+	var expected bytes.Buffer
+	expected.Write(goriffa.FourCCRIFF[:])
+	expected.Write(internal.LittleEndianUInt32Bytes(4 + 24 + 34 + 10))
+	expected.Write(test.FileType[:])
+	expected.Write(goriffa.FourCCList[:])
+	expected.Write(internal.LittleEndianUInt32Bytes(4 + 12))
+	expected.Write([]byte("INFO"))
+	expected.Write([]byte("INAM"))
+	expected.Write(internal.LittleEndianUInt32Bytes(3))
+	expected.Write([]byte{'o', 'd', 'd', 0})
+	expected.Write(goriffa.FourCCList[:])
+	expected.Write(internal.LittleEndianUInt32Bytes(4 + 12 + 10))
+	expected.Write([]byte("hdrl"))
+	expected.Write(goriffa.FourCCList[:])
+	expected.Write(internal.LittleEndianUInt32Bytes(4 + 10))
+	expected.Write([]byte("strl"))
+	expected.Write([]byte("strf"))
+	expected.Write(internal.LittleEndianUInt32Bytes(2))
+	expected.Write([]byte{1, 2})
+	expected.Write(goriffa.FourCCData[:])
+	expected.Write(internal.LittleEndianUInt32Bytes(2))
+	expected.Write([]byte{3, 4})
+
+	assert.Equal(t, expected.Bytes(), buffer.Bytes())
+
+	_, closedErr := strl.WriteChunk(strf)
+	assert.ErrorIs(t, closedErr, goriffa.ErrClosed)
+	assert.ErrorIs(t, hdrl.Close(), goriffa.ErrClosed)
+}
+
+func TestCreateListClosed(t *testing.T) {
+	var buffer test.Buffer
+	w, err := writer.New(&buffer, test.FileType)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	_, listErr := w.CreateList(internal.StringMust4Byte("INFO"))
+	assert.ErrorIs(t, listErr, goriffa.ErrClosed)
+}
+
+func TestCreateListWriteError(t *testing.T) {
+	expectedErr := errors.New("error")
+
+	mockWriter := new(MockWriter)
+	expectationsNew(mockWriter)
+	mockWriter.
+		On("Write", goriffa.FourCCList[:]).
+		Return(int(0), expectedErr).
+		Once()
+
+	w, err := writer.New(mockWriter, test.FileType)
+	assert.NoError(t, err)
+
+	_, listErr := w.CreateList(internal.StringMust4Byte("INFO"))
+	assert.ErrorIs(t, listErr, expectedErr)
+
+	mockWriter.AssertExpectations(t)
+}
+
 func expectationsNew(m *MockWriter) {
 	var fileSize [4]byte // Empty bytes
 
@@ -148,6 +427,10 @@ func expectClose(m *MockWriter) {
 		Once()
 }
 
+func fourCC(s string) internal.FourCC {
+	return internal.FourCC(internal.StringMust4Byte(s))
+}
+
 type MockWriter struct {
 	mock.Mock
 }